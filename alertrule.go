@@ -0,0 +1,83 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LogAlertRule fires when at least MinCount logs match Condition.
+type LogAlertRule struct {
+	ID           uint
+	Name         string `gorm:"uniqueIndex"`
+	Condition    string // SQL WHERE clause on logs.
+	MinCount     int
+	EvalInterval time.Duration
+	Notified     bool
+}
+
+// RuleEvaluator checks each LogAlertRule every EvalInterval and calls AlertFn
+// once per breach. The rule is re-armed when the count drops below MinCount.
+type RuleEvaluator struct {
+	DB      *gorm.DB
+	AlertFn func(rule LogAlertRule, count int64)
+}
+
+// Run evaluates all rules until ctx is done. It refuses to start if a rule
+// has no positive EvalInterval. Errors from Evaluate are logged, and the rule
+// is tried again at its next tick.
+func (e *RuleEvaluator) Run(ctx context.Context) error {
+	rules := []LogAlertRule{}
+	if err := WrapDBError(e.DB.Find(&rules)); err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		if rule.EvalInterval <= 0 {
+			return fmt.Errorf("alert rule %q: EvalInterval must be positive, got %v", rule.Name, rule.EvalInterval)
+		}
+	}
+	wg := sync.WaitGroup{}
+	for _, rule := range rules {
+		wg.Add(1)
+		go func(rule LogAlertRule) {
+			defer wg.Done()
+			ticker := time.NewTicker(rule.EvalInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+					if err := e.Evaluate(&rule); err != nil {
+						e.DB.Logger.Error(ctx, "alert rule %q: %v", rule.Name, err)
+					}
+				}
+			}
+		}(rule)
+	}
+	wg.Wait()
+	return nil
+}
+
+// SELECT count(*) FROM `logs` WHERE <condition>
+// UPDATE `log_alert_rules` SET `notified`=... WHERE `id` = ...
+func (e *RuleEvaluator) Evaluate(rule *LogAlertRule) error {
+	count := int64(0)
+	if err := WrapDBError(e.DB.Model(&Log{}).Where(rule.Condition).Count(&count)); err != nil {
+		return err
+	}
+	breached := count >= int64(rule.MinCount)
+	if breached == rule.Notified {
+		return nil
+	}
+	if err := WrapDBError(e.DB.Model(rule).Update("notified", breached)); err != nil {
+		return err
+	}
+	if breached && e.AlertFn != nil {
+		e.AlertFn(*rule, count)
+	}
+	return nil
+}
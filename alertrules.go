@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// The subset of the prometheus-operator PrometheusRule CRD we generate.
+type PrometheusRule struct {
+	APIVersion string             `yaml:"apiVersion"`
+	Kind       string             `yaml:"kind"`
+	Metadata   PrometheusMetadata `yaml:"metadata"`
+	Spec       PrometheusRuleSpec `yaml:"spec"`
+}
+
+type PrometheusMetadata struct {
+	Name   string            `yaml:"name"`
+	Labels map[string]string `yaml:"labels,omitempty"`
+}
+
+type PrometheusRuleSpec struct {
+	Groups []PrometheusRuleGroup `yaml:"groups"`
+}
+
+type PrometheusRuleGroup struct {
+	Name  string            `yaml:"name"`
+	Rules []PrometheusAlert `yaml:"rules"`
+}
+
+type PrometheusAlert struct {
+	Alert       string            `yaml:"alert"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for"`
+	Labels      map[string]string `yaml:"labels"`
+	Annotations map[string]string `yaml:"annotations"`
+}
+
+// GenerateAlertRules writes a PrometheusRule with GORMSlowQuery and
+// GORMHighErrorRate alerts over the gorm_query_duration_seconds histogram.
+func GenerateAlertRules(slowQueryThresholdMs float64, errorRateThreshold float64, outputPath string) error {
+	rule := PrometheusRule{
+		APIVersion: "monitoring.coreos.com/v1",
+		Kind:       "PrometheusRule",
+		Metadata: PrometheusMetadata{
+			Name:   "gorm-alerts",
+			Labels: map[string]string{"role": "alert-rules"},
+		},
+		Spec: PrometheusRuleSpec{Groups: []PrometheusRuleGroup{{
+			Name: "gorm",
+			Rules: []PrometheusAlert{
+				{
+					Alert: "GORMSlowQuery",
+					Expr: fmt.Sprintf(
+						"histogram_quantile(0.99, sum(rate(gorm_query_duration_seconds_bucket[5m])) by (le)) > %g",
+						slowQueryThresholdMs/1000),
+					For:    "5m",
+					Labels: map[string]string{"severity": "warning"},
+					Annotations: map[string]string{
+						"summary": fmt.Sprintf("GORM query p99 is above %gms", slowQueryThresholdMs),
+					},
+				},
+				{
+					Alert: "GORMHighErrorRate",
+					Expr: fmt.Sprintf(
+						"sum(rate(gorm_query_errors_total[5m])) / sum(rate(gorm_query_duration_seconds_count[5m])) > %g",
+						errorRateThreshold),
+					For:    "5m",
+					Labels: map[string]string{"severity": "critical"},
+					Annotations: map[string]string{
+						"summary": fmt.Sprintf("GORM error rate is above %g%%", errorRateThreshold*100),
+					},
+				},
+			},
+		}}},
+	}
+
+	f, err := os.Create(outputPath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	enc := yaml.NewEncoder(f)
+	enc.SetIndent(2)
+	if err := enc.Encode(rule); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const archiveSuffix = "_archive"
+
+// Archive moves the logs older than retention, soft-deleted ones included,
+// from logs to logs_archive in one transaction and returns how many moved.
+// Their log_details and log_tags rows move along, to log_details_archive and
+// log_tags_archive. An archive table is created on first use with the columns
+// of its table, but none of its indexes, so archived rows never collide on the
+// unique ones; columns added to the table since are added to the archive
+// before copying.
+func Archive(db *gorm.DB, retention time.Duration) (archived int64, err error) {
+	cutoff := time.Now().Add(-retention)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		ofArchivedLogs := "log_id IN (SELECT id FROM logs WHERE time < ?)"
+		for _, table := range []string{"log_details", "log_tags"} {
+			if !tx.Migrator().HasTable(table) {
+				continue
+			}
+			if _, err := archiveRows(tx, table, ofArchivedLogs, cutoff); err != nil {
+				return err
+			}
+			if err := WrapDBError(tx.Exec("DELETE FROM ? WHERE "+ofArchivedLogs, clause.Table{Name: table}, cutoff)); err != nil {
+				return err
+			}
+		}
+
+		copied, err := archiveRows(tx, "logs", "time < ?", cutoff)
+		if err != nil {
+			return err
+		}
+		if err := WrapDBError(tx.Unscoped().Where("time < ?", cutoff).Delete(&Log{})); err != nil {
+			return err
+		}
+		archived = copied
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return archived, nil
+}
+
+// archiveRows copies the rows of table matching where into its archive table,
+// creating it or adding the columns it lacks first, and returns how many it
+// copied.
+func archiveRows(tx *gorm.DB, table, where string, args ...interface{}) (int64, error) {
+	archive := table + archiveSuffix
+	err := WrapDBError(tx.Exec("CREATE TABLE IF NOT EXISTS ? AS SELECT * FROM ? WHERE 1 = 0",
+		clause.Table{Name: archive}, clause.Table{Name: table}))
+	if err != nil {
+		return 0, err
+	}
+	columnTypes, err := tx.Migrator().ColumnTypes(table)
+	if err != nil {
+		return 0, err
+	}
+	archiveTypes, err := tx.Migrator().ColumnTypes(archive)
+	if err != nil {
+		return 0, err
+	}
+	archived := map[string]bool{}
+	for _, c := range archiveTypes {
+		archived[c.Name()] = true
+	}
+
+	columns := make([]string, len(columnTypes))
+	for i, c := range columnTypes {
+		if !archived[c.Name()] {
+			added := tx.Exec("ALTER TABLE ? ADD COLUMN ? "+c.DatabaseTypeName(),
+				clause.Table{Name: archive}, clause.Column{Name: c.Name()})
+			if err := WrapDBError(added); err != nil {
+				return 0, err
+			}
+		}
+		columns[i] = tx.Statement.Quote(c.Name())
+	}
+	list := strings.Join(columns, ",")
+
+	vars := append([]interface{}{clause.Table{Name: archive}, clause.Table{Name: table}}, args...)
+	copied := tx.Exec("INSERT INTO ? ("+list+") SELECT "+list+" FROM ? WHERE "+where, vars...)
+	if err := WrapDBError(copied); err != nil {
+		return 0, err
+	}
+	return copied.RowsAffected, nil
+}
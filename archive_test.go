@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestArchiveMovesDetailsTagsAndNewColumns(t *testing.T) {
+	db, cleanup, err := InMemoryDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	ancient := time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC)
+	first := Log{Time: ancient, Msg: "first", LogDetails: []LogDetail{{DetailMsg: "first detail"}}, Tags: []Tag{{Name: "old"}}}
+	if err := db.Create(&first).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Archive(db, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	// logs gains a column after logs_archive was created.
+	if err := db.Exec("ALTER TABLE logs ADD COLUMN source text").Error; err != nil {
+		t.Fatal(err)
+	}
+	second := Log{Time: ancient.Add(time.Minute), Msg: "second", LogDetails: []LogDetail{{DetailMsg: "second detail"}}}
+	if err := db.Create(&second).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("UPDATE logs SET source = ? WHERE id = ?", "syslog", second.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	archived, err := Archive(db, time.Hour)
+	if err != nil || archived != 1 {
+		t.Fatalf("got %d, %v; want 1, nil", archived, err)
+	}
+
+	source := ""
+	if err := db.Raw("SELECT source FROM logs_archive WHERE msg = ?", "second").Scan(&source).Error; err != nil {
+		t.Fatal(err)
+	}
+	if source != "syslog" {
+		t.Errorf("got archived source %q; want syslog", source)
+	}
+	for table, want := range map[string]int64{
+		"log_details": 0, "log_details_archive": 2,
+		"log_tags": 0, "log_tags_archive": 1,
+	} {
+		count := int64(0)
+		if err := db.Table(table).Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count != want {
+			t.Errorf("got %d rows in %s; want %d", count, table, want)
+		}
+	}
+}
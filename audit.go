@@ -0,0 +1,35 @@
+package main
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type auditUserKey struct{}
+
+// WithAuditUser returns a context whose creates and updates, through
+// db.WithContext(ctx), are attributed to userID.
+func WithAuditUser(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, auditUserKey{}, userID)
+}
+
+func auditUser(tx *gorm.DB) (string, bool) {
+	userID, ok := tx.Statement.Context.Value(auditUserKey{}).(string)
+	return userID, ok && userID != ""
+}
+
+func (u *Log) auditCreate(tx *gorm.DB) {
+	if userID, ok := auditUser(tx); ok {
+		u.CreatedBy = userID
+		u.UpdatedBy = userID
+	}
+}
+
+// auditUpdate goes through SetColumn so that Update and Updates write it too,
+// not just Save.
+func (u *Log) auditUpdate(tx *gorm.DB) {
+	if userID, ok := auditUser(tx); ok {
+		tx.Statement.SetColumn("UpdatedBy", userID)
+	}
+}
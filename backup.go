@@ -0,0 +1,24 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gorm.io/gorm"
+)
+
+var ErrUnsupportedDriver = gorm.ErrUnsupportedDriver
+
+// Backup snapshots an open SQLite database to destPath, which must not exist
+// yet. Other drivers get ErrUnsupportedDriver.
+func Backup(db *gorm.DB, destPath string) error {
+	if db.Dialector.Name() != "sqlite" {
+		return fmt.Errorf("backup with %s: %w", db.Dialector.Name(), ErrUnsupportedDriver)
+	}
+	if _, err := os.Stat(destPath); err == nil {
+		return fmt.Errorf("backup to %s: %w", destPath, os.ErrExist)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+	return BackupDB(db, destPath)
+}
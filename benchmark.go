@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// BenchmarkResult is what BenchmarkQuery measured. Plan and RowsExamined are
+// for the statements of one run of the query; Err is set if they couldn't be
+// explained.
+type BenchmarkResult struct {
+	Iterations    int
+	Min, Max, Avg time.Duration
+	RowsExamined  int64
+	Plan          []string
+	Err           error
+}
+
+// BenchmarkQuery runs query iterations times with logging silenced and times
+// each run. One untimed run before them records the statements, which are
+// then explained.
+//
+// RowsExamined comes from the plan. Postgres reports it: the actual rows of
+// every node of EXPLAIN ANALYZE are added up. SQLite's plan has no row counts,
+// so a SCAN of a table counts all its rows and a SEARCH counts the rows the
+// statement returned.
+func BenchmarkQuery(db *gorm.DB, query func(*gorm.DB), iterations int) BenchmarkResult {
+	silent := db.Session(&gorm.Session{Logger: db.Logger.LogMode(logger.Silent)})
+
+	statements := &benchmarkStatements{}
+	query(silent.Session(&gorm.Session{Logger: statementLogger{Interface: silent.Logger, statements: statements}}))
+
+	result := BenchmarkResult{Iterations: iterations}
+	var total time.Duration
+	for i := 0; i < iterations; i++ {
+		start := time.Now()
+		query(silent)
+		elapsed := time.Since(start)
+		total += elapsed
+		if i == 0 || elapsed < result.Min {
+			result.Min = elapsed
+		}
+		if elapsed > result.Max {
+			result.Max = elapsed
+		}
+	}
+	if iterations > 0 {
+		result.Avg = total / time.Duration(iterations)
+	}
+
+	for _, stmt := range statements.list {
+		plan, err := explainSQL(silent, stmt.sql)
+		if err != nil {
+			result.Err = err
+			break
+		}
+		result.Plan = append(result.Plan, plan...)
+		examined, err := rowsExamined(silent, plan, stmt.rows)
+		if err != nil {
+			result.Err = err
+			break
+		}
+		result.RowsExamined += examined
+	}
+	return result
+}
+
+var actualRows = regexp.MustCompile(`actual [^)]*rows=(\d+)`)
+
+func rowsExamined(db *gorm.DB, plan []string, returned int64) (int64, error) {
+	examined := int64(0)
+	for _, line := range plan {
+		if db.Dialector.Name() != "sqlite" {
+			if m := actualRows.FindStringSubmatch(line); m != nil {
+				n, _ := strconv.ParseInt(m[1], 10, 64)
+				examined += n
+			}
+			continue
+		}
+		// "SCAN logs", or "SCAN TABLE logs" before SQLite 3.36.
+		fields := strings.Fields(strings.Replace(line, " TABLE ", " ", 1))
+		switch {
+		case len(fields) >= 2 && fields[0] == "SCAN" && !strings.HasPrefix(fields[1], "("):
+			count := int64(0)
+			if err := db.Table(fields[1]).Count(&count).Error; err != nil {
+				return 0, err
+			}
+			examined += count
+		case len(fields) >= 1 && fields[0] == "SEARCH":
+			examined += returned
+		}
+	}
+	return examined, nil
+}
+
+type benchmarkStatement struct {
+	sql  string
+	rows int64
+}
+
+type benchmarkStatements struct {
+	list []benchmarkStatement
+}
+
+// statementLogger records the statements it traces, with their values inlined.
+type statementLogger struct {
+	logger.Interface
+	statements *benchmarkStatements
+}
+
+func (l statementLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return statementLogger{Interface: l.Interface.LogMode(level), statements: l.statements}
+}
+
+func (l statementLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, rows := fc()
+	if err == nil {
+		l.statements.list = append(l.statements.list, benchmarkStatement{sql: sql, rows: rows})
+	}
+	l.Interface.Trace(ctx, begin, func() (string, int64) { return sql, rows }, err)
+}
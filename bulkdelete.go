@@ -0,0 +1,42 @@
+package main
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// BulkDelete deletes the logs with the given ids, at most batchSize per
+// statement so a large slice doesn't exceed SQLite's bound-variable limit.
+// All batches run in one transaction: if any fails, none of them stick and
+// the count is 0. Log and its LogDetails are soft-deleted like db.Delete;
+// pass db.Unscoped() to remove the rows.
+func BulkDelete(db *gorm.DB, ids []uint, batchSize int) (int64, error) {
+	if batchSize <= 0 {
+		return 0, errors.New("BulkDelete: batchSize must be positive")
+	}
+	var deleted int64
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(ids); start += batchSize {
+			end := start + batchSize
+			if end > len(ids) {
+				end = len(ids)
+			}
+			// Deleting by id never runs AfterDelete's cascade, so delete the
+			// details here, before the logs they reference.
+			if err := WrapDBError(tx.Where("log_id IN ?", ids[start:end]).Delete(&LogDetail{})); err != nil {
+				return err
+			}
+			result := tx.Delete(&Log{}, ids[start:end])
+			if err := WrapDBError(result); err != nil {
+				return err
+			}
+			deleted += result.RowsAffected
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
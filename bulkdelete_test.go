@@ -0,0 +1,48 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBulkDeleteCascadesToDetails(t *testing.T) {
+	for _, unscoped := range []bool{false, true} {
+		db, cleanup, err := InMemoryDB()
+		if err != nil {
+			t.Fatal(err)
+		}
+		logs := []Log{
+			{Time: time.Now(), Msg: "first", LogDetails: []LogDetail{{DetailMsg: "a"}, {DetailMsg: "b"}}},
+			{Time: time.Now(), Msg: "second", LogDetails: []LogDetail{{DetailMsg: "c"}}},
+			{Time: time.Now(), Msg: "kept", LogDetails: []LogDetail{{DetailMsg: "d"}}},
+		}
+		if err := db.Create(&logs).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		tx := db
+		if unscoped {
+			tx = db.Unscoped()
+		}
+		deleted, err := BulkDelete(tx, []uint{logs[0].ID, logs[1].ID}, 1)
+		if err != nil || deleted != 2 {
+			t.Fatalf("unscoped %v: got %d, %v; want 2, nil", unscoped, deleted, err)
+		}
+
+		details := []LogDetail{}
+		if err := db.Find(&details).Error; err != nil {
+			t.Fatal(err)
+		}
+		if len(details) != 1 || details[0].DetailMsg != "d" {
+			t.Errorf("unscoped %v: got details %+v; want only d", unscoped, details)
+		}
+		stored := int64(0)
+		if err := db.Unscoped().Model(&LogDetail{}).Count(&stored).Error; err != nil {
+			t.Fatal(err)
+		}
+		if want := map[bool]int64{false: 4, true: 1}[unscoped]; stored != want {
+			t.Errorf("unscoped %v: got %d detail rows stored; want %d", unscoped, stored, want)
+		}
+		cleanup()
+	}
+}
@@ -0,0 +1,110 @@
+//go:build redis
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
+)
+
+// RedisCacheInvalidator drops the cached copies of a Log from Redis whenever
+// it is created, updated or deleted through GORM.
+type RedisCacheInvalidator struct {
+	Client *redis.Client
+}
+
+func (r RedisCacheInvalidator) Name() string {
+	return "redis_cache_invalidator"
+}
+
+func (r RedisCacheInvalidator) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().After("gorm:after_create").Register("redis:invalidate", r.invalidate),
+		cb.Update().After("gorm:after_update").Register("redis:invalidate", r.invalidate),
+		cb.Delete().After("gorm:after_delete").Register("redis:invalidate", r.invalidate),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r RedisCacheInvalidator) invalidate(tx *gorm.DB) {
+	if tx.Error != nil || tx.Statement.Table != "logs" {
+		return
+	}
+	ctx := tx.Statement.Context
+	for _, id := range logIDs(tx.Statement.ReflectValue) {
+		r.Client.Del(ctx, "log:"+strconv.Itoa(int(id)))
+	}
+	r.Client.Del(ctx, "logs:all")
+}
+
+// logIDs collects the non-zero IDs of a Log, *Log or []Log value.
+func logIDs(v reflect.Value) []uint {
+	ids := []uint{}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			ids = append(ids, logIDs(reflect.Indirect(v.Index(i)))...)
+		}
+	case reflect.Struct:
+		if log, ok := v.Interface().(Log); ok && log.ID != 0 {
+			ids = append(ids, log.ID)
+		}
+	}
+	return ids
+}
+
+// WarmRedisCache stores every log as JSON under "log:<id>".
+func WarmRedisCache(db *gorm.DB, client *redis.Client) error {
+	ctx := context.Background()
+	logs := []Log{}
+	return WrapDBError(db.FindInBatches(&logs, 500, func(tx *gorm.DB, _ int) error {
+		for _, log := range logs {
+			value, err := json.Marshal(log)
+			if err != nil {
+				return err
+			}
+			if err := client.Set(ctx, "log:"+strconv.Itoa(int(log.ID)), value, 0).Err(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// GET log:1 (hit after warming), UPDATE `logs` ..., GET log:1 (miss)
+func redisDemo(db *gorm.DB) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	defer client.Close()
+	if err := client.Ping(ctx).Err(); err != nil {
+		fmt.Println("redis:", err)
+		return
+	}
+
+	cachedDB, _ := gorm.Open(db.Dialector, &gorm.Config{})
+	cachedDB.Use(RedisCacheInvalidator{Client: client})
+	if err := WarmRedisCache(cachedDB, client); err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	_, err := client.Get(ctx, "log:1").Result()
+	fmt.Println("hit:", err == nil) // hit: true
+
+	log := Log{}
+	cachedDB.First(&log, 1)
+	cachedDB.Model(&log).Update("level", log.Level)
+	_, err = client.Get(ctx, "log:1").Result()
+	fmt.Println("miss:", err == redis.Nil) // miss: true
+}
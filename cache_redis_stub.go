@@ -0,0 +1,8 @@
+//go:build !redis
+
+package main
+
+import "gorm.io/gorm"
+
+// Build with -tags redis (and a Redis on localhost:6379) to run the Redis demo.
+func redisDemo(db *gorm.DB) {}
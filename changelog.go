@@ -0,0 +1,85 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ChangeLog is one field of one row changed by a Save.
+type ChangeLog struct {
+	ID        uint
+	TableName string `gorm:"index:idx_change_logs_record"`
+	RecordID  uint   `gorm:"index:idx_change_logs_record"`
+	FieldName string
+	OldValue  string
+	NewValue  string
+	ChangedAt time.Time
+	ChangedBy string // from WithAuditUser
+}
+
+const changeLogSnapshotKey = "change_log:before"
+
+// snapshotForChangeLog loads the row u is about to overwrite. Only Save, or
+// Updates(&log), writes the whole struct; other updates hold just a few
+// fields, which would make everything else look changed.
+func (u *Log) snapshotForChangeLog(tx *gorm.DB) error {
+	if dest, ok := tx.Statement.Dest.(*Log); !ok || dest != u || u.ID == 0 {
+		return nil
+	}
+	before := Log{}
+	// SkipHooks, or AfterFind would normalise the old Msg. It goes on once
+	// Unscoped has started the new statement, which would drop it.
+	err := tx.Session(&gorm.Session{NewDB: true}).Unscoped().
+		Session(&gorm.Session{SkipHooks: true}).First(&before, u.ID).Error
+	if err != nil {
+		return err
+	}
+	tx.Statement.DB.InstanceSet(changeLogSnapshotKey, before)
+	return nil
+}
+
+// recordChanges inserts a ChangeLog per field that differs from the snapshot,
+// in the transaction of the update. Version is left out; it always changes,
+// and so are the fields the update omitted.
+func (u *Log) recordChanges(tx *gorm.DB) error {
+	v, ok := tx.Statement.DB.InstanceGet(changeLogSnapshotKey)
+	if !ok || tx.Statement.DB.RowsAffected == 0 {
+		return nil
+	}
+	changedBy, _ := auditUser(tx)
+	now := time.Now()
+	written, _ := tx.Statement.SelectAndOmitColumns(false, true)
+	changes := []ChangeLog{}
+	for _, change := range Diff(v.(Log), *u) {
+		if change.Field == "Version" {
+			continue
+		}
+		if field := tx.Statement.Schema.LookUpField(change.Field); field != nil {
+			if w, ok := written[field.DBName]; ok && !w {
+				continue // Omitted, such as the msg keepStoredMsg leaves out.
+			}
+		}
+		changes = append(changes, ChangeLog{
+			TableName: tx.Statement.Table,
+			RecordID:  u.ID,
+			FieldName: change.Field,
+			OldValue:  changeLogValue(change.Old),
+			NewValue:  changeLogValue(change.New),
+			ChangedAt: now,
+			ChangedBy: changedBy,
+		})
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return tx.Session(&gorm.Session{NewDB: true}).Create(&changes).Error
+}
+
+func changeLogValue(v interface{}) string {
+	if t, ok := v.(time.Time); ok {
+		return t.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
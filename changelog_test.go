@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSaveRecordsMsgChangeAgainstStoredMsg(t *testing.T) {
+	db, cleanup, err := InMemoryDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	log := Log{Time: time.Now(), Msg: "  Disk FULL on /var  "}
+	if err := db.Create(&log).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := Log{}
+	if err := db.First(&loaded, log.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	loaded.Msg = "disk full on /home"
+	loaded.Level = 3
+	if err := db.Save(&loaded).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	changes := []ChangeLog{}
+	if err := db.Where("record_id = ?", log.ID).Order("field_name").Find(&changes).Error; err != nil {
+		t.Fatal(err)
+	}
+	got := map[string][2]string{}
+	for _, c := range changes {
+		got[c.FieldName] = [2]string{c.OldValue, c.NewValue}
+	}
+	if want := [2]string{"0", "3"}; got["Level"] != want {
+		t.Errorf("Level change %q, want %q", got["Level"], want)
+	}
+	// The old value is the stored Msg, not the one AfterFind normalised.
+	if want := [2]string{"  Disk FULL on /var  ", "disk full on /home"}; got["Msg"] != want {
+		t.Errorf("Msg change %q, want %q", got["Msg"], want)
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type TimeSeriesPoint struct {
+	Bucket time.Time
+	Count  int64
+}
+
+// SELECT strftime('%Y-%m-%d %H:%M:00', time) AS bucket, count(*) AS count
+// FROM `logs` WHERE time IS NOT NULL GROUP BY `bucket` ORDER BY bucket
+//
+// Logs without a time have no bucket and are left out.
+func LogsPerMinute(db *gorm.DB) ([]TimeSeriesPoint, error) {
+	rows := []struct {
+		Bucket string
+		Count  int64
+	}{}
+	err := WrapDBError(db.
+		Model(&Log{}).
+		Select("strftime('%Y-%m-%d %H:%M:00', time) AS bucket, count(*) AS count").
+		Where("time IS NOT NULL").
+		Group("bucket").
+		Order("bucket").
+		Find(&rows))
+	if err != nil {
+		return nil, err
+	}
+	points := make([]TimeSeriesPoint, 0, len(rows))
+	for _, row := range rows {
+		bucket, err := time.Parse("2006-01-02 15:04:05", row.Bucket)
+		if err != nil {
+			return nil, err
+		}
+		points = append(points, TimeSeriesPoint{Bucket: bucket, Count: row.Count})
+	}
+	return points, nil
+}
+
+// HourlyPoints sums sorted points into one point per hour.
+func HourlyPoints(points []TimeSeriesPoint) []TimeSeriesPoint {
+	hourly := []TimeSeriesPoint{}
+	for _, p := range points {
+		hour := p.Bucket.Truncate(time.Hour)
+		if n := len(hourly); n > 0 && hourly[n-1].Bucket.Equal(hour) {
+			hourly[n-1].Count += p.Count
+			continue
+		}
+		hourly = append(hourly, TimeSeriesPoint{Bucket: hour, Count: p.Count})
+	}
+	return hourly
+}
+
+const chartMargin = 40
+
+// TimeSeriesChart draws one bar per point, with hours along the x-axis and
+// counts up the y-axis.
+func TimeSeriesChart(points []TimeSeriesPoint, width, height int) (string, error) {
+	if width <= 2*chartMargin || height <= 2*chartMargin {
+		return "", fmt.Errorf("chart must be larger than %dx%d", 2*chartMargin, 2*chartMargin)
+	}
+	if len(points) == 0 {
+		return "", errors.New("no points to chart")
+	}
+	maxCount := int64(1)
+	for _, p := range points {
+		if p.Count > maxCount {
+			maxCount = p.Count
+		}
+	}
+	plotW, plotH := width-2*chartMargin, height-2*chartMargin
+	slot := float64(plotW) / float64(len(points))
+	bottom := chartMargin + plotH
+	labelFormat := "15:04"
+	if first, last := points[0].Bucket, points[len(points)-1].Bucket; first.YearDay() != last.YearDay() || first.Year() != last.Year() {
+		labelFormat = "01-02 15:04"
+	}
+
+	b := strings.Builder{}
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="10">`+"\n", width, height, width, height)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="black"/>`+"\n", chartMargin, chartMargin, chartMargin, bottom)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="black"/>`+"\n", chartMargin, bottom, chartMargin+plotW, bottom)
+	for _, tick := range []int64{0, maxCount / 2, maxCount} {
+		y := float64(bottom) - float64(tick)/float64(maxCount)*float64(plotH)
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end" dominant-baseline="middle">%d</text>`+"\n", chartMargin-4, y, tick)
+	}
+	for i, p := range points {
+		barH := float64(p.Count) / float64(maxCount) * float64(plotH)
+		x := float64(chartMargin) + float64(i)*slot
+		fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" fill="steelblue"/>`+"\n", x+slot*0.1, float64(bottom)-barH, slot*0.8, barH)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`+"\n", x+slot/2, bottom+14, p.Bucket.Format(labelFormat))
+	}
+	b.WriteString("</svg>\n")
+	return b.String(), nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLogsPerMinuteSkipsLogsWithoutTime(t *testing.T) {
+	db, cleanup, err := InMemoryDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	timed := Log{Time: time.Date(2022, 10, 20, 11, 30, 15, 0, time.UTC), Msg: "timed", Level: 1}
+	if err := db.Create(&timed).Error; err != nil {
+		t.Fatal(err)
+	}
+	untimed := Log{Msg: "untimed", Level: 1}
+	if err := db.Select("Msg", "Level").Create(&untimed).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	points, err := LogsPerMinute(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := time.Date(2022, 10, 20, 11, 30, 0, 0, time.UTC)
+	if len(points) != 1 || !points[0].Bucket.Equal(want) || points[0].Count != 1 {
+		t.Fatalf("got %+v; want one point at %v with count 1", points, want)
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import "gorm.io/gorm"
+
+// ChunkLogs splits logs into sub-slices of at most n items. The chunks share
+// the backing array of logs; nothing is copied.
+func ChunkLogs(logs []Log, n int) [][]Log {
+	if n <= 0 {
+		panic("ChunkLogs: n must be positive")
+	}
+	if len(logs) == 0 {
+		return nil
+	}
+	chunks := make([][]Log, 0, (len(logs)+n-1)/n)
+	for start := 0; start < len(logs); start += n {
+		end := start + n
+		if end > len(logs) {
+			end = len(logs)
+		}
+		chunks = append(chunks, logs[start:end:end])
+	}
+	return chunks
+}
+
+// ProcessChunked calls fn once per batch of chunkSize logs. The slice passed to
+// fn is reused for the next batch, so copy it if you need to keep it.
+func ProcessChunked(db *gorm.DB, chunkSize int, fn func([]Log) error) error {
+	batch := []Log{}
+	return WrapDBError(db.FindInBatches(&batch, chunkSize, func(tx *gorm.DB, _ int) error {
+		return fn(batch)
+	}))
+}
@@ -0,0 +1,166 @@
+// Command migrate-seed applies the SQL migrations in --migrations-dir that
+// schema_migrations doesn't list yet, and then the fixtures in --seed-file, in
+// one transaction. A failing seed rolls the migrations back too.
+//
+//	go run ./cmd/migrate-seed --dsn log.db --migrations-dir migrations --seed-file seed.yaml
+//
+// The seed file lists tables in insertion order:
+//
+//	# seed.yaml
+//	- table: logs
+//	  rows:
+//	    - {id: 1, msg: seeded, level: 1}
+//	- table: log_details
+//	  rows:
+//	    - {log_id: 1, detail_msg: seeded detail}
+//
+// With --dry-run everything runs and is printed, then rolled back.
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var errDryRun = errors.New("dry run")
+
+// SchemaMigration is a row of the schema_migrations table the app's
+// RunMigrations keeps, so the two skip each other's applied files.
+type SchemaMigration struct {
+	Filename  string `gorm:"primaryKey"`
+	Checksum  string // hex sha256 of the file
+	AppliedAt time.Time
+}
+
+// MigrationRunner executes the *.sql files in Dir in lexicographic order,
+// recording each in schema_migrations. Files already recorded are skipped, and
+// one whose content changed since is an error.
+type MigrationRunner struct {
+	DB  *gorm.DB
+	Dir string
+}
+
+func (r MigrationRunner) Migrate() error {
+	if err := r.DB.AutoMigrate(&SchemaMigration{}); err != nil {
+		return err
+	}
+	applied := []SchemaMigration{}
+	if err := r.DB.Find(&applied).Error; err != nil {
+		return err
+	}
+	checksums := map[string]string{}
+	for _, m := range applied {
+		checksums[m.Filename] = m.Checksum
+	}
+
+	files, err := filepath.Glob(filepath.Join(r.Dir, "*.sql"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		ddl, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(ddl)
+		checksum := hex.EncodeToString(sum[:])
+		name := filepath.Base(file)
+		if old, ok := checksums[name]; ok {
+			if old != checksum {
+				return fmt.Errorf("%s: migration changed after it was applied", file)
+			}
+			continue
+		}
+		if err := r.DB.Exec(string(ddl)).Error; err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if err := r.DB.Create(&SchemaMigration{Filename: name, Checksum: checksum, AppliedAt: time.Now()}).Error; err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+	}
+	return nil
+}
+
+type seedTable struct {
+	Table string                   `yaml:"table"`
+	Rows  []map[string]interface{} `yaml:"rows"`
+}
+
+// SeedFromYAML inserts the rows of each table in the order they are listed.
+func SeedFromYAML(db *gorm.DB, path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	tables := []seedTable{}
+	if err := yaml.Unmarshal(content, &tables); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for _, t := range tables {
+		for _, row := range t.Rows {
+			if err := db.Table(t.Table).Create(row).Error; err != nil {
+				return fmt.Errorf("seeding %s: %w", t.Table, err)
+			}
+		}
+	}
+	return nil
+}
+
+func run(dsn, migrationsDir, seedFile string, dryRun bool) error {
+	logLevel := logger.Warn
+	if dryRun {
+		logLevel = logger.Info
+	}
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logLevel),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := (MigrationRunner{DB: tx, Dir: migrationsDir}).Migrate(); err != nil {
+			return err
+		}
+		if seedFile != "" {
+			if err := SeedFromYAML(tx, seedFile); err != nil {
+				return err
+			}
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if errors.Is(err, errDryRun) {
+		fmt.Println("dry run: rolled back")
+		return nil
+	}
+	return err
+}
+
+func main() {
+	dsn := flag.String("dsn", "log.db", "SQLite database file")
+	migrationsDir := flag.String("migrations-dir", "migrations", "directory of *.sql migrations")
+	seedFile := flag.String("seed-file", "", "YAML fixture to seed after migrating")
+	dryRun := flag.Bool("dry-run", false, "print the SQL and roll back instead of committing")
+	flag.Parse()
+
+	if err := run(*dsn, *migrationsDir, *seedFile, *dryRun); err != nil {
+		fmt.Fprintln(os.Stderr, "migrate-seed:", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+	"gorm.io/gorm"
+)
+
+// EncodeAll is safe for concurrent use, so one encoder serves every call.
+var zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(3)))
+
+// CompressedSize is the length of l as zstd-compressed (level 3) JSON.
+func (l Log) CompressedSize() (int, error) {
+	raw, err := json.Marshal(l)
+	if err != nil {
+		return 0, err
+	}
+	return len(zstdEncoder.EncodeAll(raw, nil)), nil
+}
+
+// FindLargestLogs returns the n logs with the largest CompressedSize.
+func FindLargestLogs(db *gorm.DB, n int) ([]Log, error) {
+	if n < 0 {
+		return nil, errors.New("FindLargestLogs: n must not be negative")
+	}
+	logs := []Log{}
+	if err := WrapDBError(db.Find(&logs)); err != nil {
+		return nil, err
+	}
+
+	sizes := make([]int, len(logs))
+	errs := make([]error, len(logs))
+	indexes := make(chan int)
+	wg := sync.WaitGroup{}
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				sizes[i], errs[i] = logs[i].CompressedSize()
+			}
+		}()
+	}
+	for i := range logs {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	order := make([]int, len(logs))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return sizes[order[a]] > sizes[order[b]] })
+	if n > len(order) {
+		n = len(order)
+	}
+	largest := make([]Log, 0, n)
+	for _, i := range order[:n] {
+		largest = append(largest, logs[i])
+	}
+	return largest, nil
+}
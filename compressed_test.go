@@ -0,0 +1,15 @@
+package main
+
+import "testing"
+
+func TestFindLargestLogsRejectsNegativeN(t *testing.T) {
+	db, cleanup, err := InMemoryDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	if _, err := FindLargestLogs(db, -1); err == nil {
+		t.Error("got no error for n = -1")
+	}
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var ErrCreateOnlyField = errors.New("field can only be set on creation")
+
+// CreateOnlyPlugin rejects updates that change a field tagged
+// gorm_extra:"create_only". It relies on Statement.Changed, so it catches
+// Update and Updates but not Save, which always writes every column.
+type CreateOnlyPlugin struct{}
+
+func (CreateOnlyPlugin) Name() string {
+	return "create_only"
+}
+
+func (CreateOnlyPlugin) Initialize(db *gorm.DB) error {
+	return db.Callback().Update().Before("gorm:before_update").Register("create_only:check", func(tx *gorm.DB) {
+		if tx.Statement.Schema == nil {
+			return
+		}
+		for _, field := range tx.Statement.Schema.Fields {
+			if hasExtraTag(field.Tag.Get("gorm_extra"), "create_only") && tx.Statement.Changed(field.Name) {
+				tx.AddError(fmt.Errorf("%s: %w", field.Name, ErrCreateOnlyField))
+				return
+			}
+		}
+	})
+}
+
+func hasExtraTag(tag, want string) bool {
+	for _, t := range strings.Split(tag, ",") {
+		if strings.TrimSpace(t) == want {
+			return true
+		}
+	}
+	return false
+}
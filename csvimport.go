@@ -0,0 +1,176 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CSVMapping maps a CSV header to a Log field name such as "Msg".
+type CSVMapping struct {
+	CSVColumn string
+	LogField  string
+}
+
+// ImportError is a row that was skipped. Line is 1-based and counts the
+// header; it is 0 for an error that isn't about one line.
+type ImportError struct {
+	Line int
+	Raw  string
+	Err  error
+}
+
+func (e ImportError) Error() string {
+	msg := fmt.Sprint(e.Err)
+	if e.Line > 0 {
+		msg = fmt.Sprintf("line %d: %s", e.Line, msg)
+	}
+	if e.Raw != "" {
+		msg += " (" + e.Raw + ")"
+	}
+	return msg
+}
+
+var errMissingMsg = errors.New("msg is required")
+
+// ImportCSVWithMapping inserts one Log per valid CSV row and returns how many
+// were inserted. Bad rows are reported without stopping the import. The
+// inserts share a transaction: if a batch fails, nothing is inserted and the
+// error names the lines of that batch.
+func ImportCSVWithMapping(db *gorm.DB, r io.Reader, mapping []CSVMapping, batchSize int) (int, []ImportError) {
+	if batchSize <= 0 {
+		return 0, []ImportError{{Err: errors.New("ImportCSVWithMapping: batchSize must be positive")}}
+	}
+	reader := csv.NewReader(r)
+	header, err := reader.Read()
+	if err != nil {
+		return 0, []ImportError{{Line: 1, Err: err}}
+	}
+	positions, err := csvPositions(header, mapping)
+	if err != nil {
+		return 0, []ImportError{{Line: 1, Raw: strings.Join(header, ","), Err: err}}
+	}
+
+	logs := []Log{}
+	lines := []int{} // of each log
+	importErrors := []ImportError{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			importErrors = append(importErrors, csvReadError(err, record))
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+		log, err := logFromCSV(record, mapping, positions)
+		if err != nil {
+			importErrors = append(importErrors, ImportError{Line: line, Raw: strings.Join(record, ","), Err: err})
+			continue
+		}
+		logs = append(logs, log)
+		lines = append(lines, line)
+	}
+
+	if len(logs) == 0 {
+		return 0, importErrors
+	}
+	result := db.CreateInBatches(&logs, batchSize)
+	if err := WrapDBError(result); err != nil {
+		// Every batch before the failing one went in whole.
+		start := int(result.RowsAffected) / batchSize * batchSize
+		if start >= len(logs) {
+			start = (len(logs) - 1) / batchSize * batchSize
+		}
+		end := start + batchSize
+		if end > len(logs) {
+			end = len(logs)
+		}
+		err = fmt.Errorf("batch of lines %d-%d rolled back: %w", lines[start], lines[end-1], err)
+		return 0, append(importErrors, ImportError{Line: lines[start], Err: err})
+	}
+	return int(result.RowsAffected), importErrors
+}
+
+func csvReadError(err error, record []string) ImportError {
+	importError := ImportError{Raw: strings.Join(record, ","), Err: err}
+	var parseError *csv.ParseError
+	if errors.As(err, &parseError) {
+		importError.Line = parseError.Line
+	}
+	return importError
+}
+
+// csvPositions returns the CSV column index of each mapping.
+func csvPositions(header []string, mapping []CSVMapping) ([]int, error) {
+	index := map[string]int{}
+	for i, name := range header {
+		index[strings.TrimSpace(name)] = i
+	}
+	positions := make([]int, len(mapping))
+	for i, m := range mapping {
+		pos, ok := index[m.CSVColumn]
+		if !ok {
+			return nil, fmt.Errorf("column %q not in header", m.CSVColumn)
+		}
+		if f, ok := reflect.TypeOf(Log{}).FieldByName(m.LogField); !ok || !f.IsExported() {
+			return nil, fmt.Errorf("Log has no field %q", m.LogField)
+		}
+		positions[i] = pos
+	}
+	return positions, nil
+}
+
+func logFromCSV(record []string, mapping []CSVMapping, positions []int) (Log, error) {
+	log := Log{}
+	v := reflect.ValueOf(&log).Elem()
+	for i, m := range mapping {
+		if err := setField(v.FieldByName(m.LogField), record[positions[i]]); err != nil {
+			return Log{}, fmt.Errorf("%s: %w", m.LogField, err)
+		}
+	}
+	if log.Msg == "" {
+		return Log{}, errMissingMsg
+	}
+	return log, nil
+}
+
+// setField parses s into a string, integer or time.Time (RFC 3339) field.
+func setField(f reflect.Value, s string) error {
+	s = strings.TrimSpace(s)
+	if _, ok := f.Interface().(time.Time); ok {
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return err
+		}
+		f.Set(reflect.ValueOf(t))
+		return nil
+	}
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	default:
+		return fmt.Errorf("unsupported field type %s", f.Type())
+	}
+	return nil
+}
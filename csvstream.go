@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// StreamCSVImport is ImportCSVWithMapping for files too big to hold in
+// memory. The header names Log fields directly (e.g. time,msg,level), and at
+// most chunkSize logs are buffered before being inserted. A chunkSize below 1
+// imports nothing.
+func StreamCSVImport(db *gorm.DB, r io.Reader, chunkSize int) (int64, []ImportError) {
+	if chunkSize <= 0 {
+		return 0, []ImportError{{Err: errors.New("StreamCSVImport: chunkSize must be positive")}}
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	if !scanner.Scan() {
+		return 0, []ImportError{{Line: 1, Err: scannerErr(scanner)}}
+	}
+	header, err := parseCSVLine(scanner.Text())
+	if err != nil {
+		return 0, []ImportError{{Line: 1, Raw: scanner.Text(), Err: err}}
+	}
+	mapping := headerMapping(header)
+	positions, err := csvPositions(header, mapping)
+	if err != nil {
+		return 0, []ImportError{{Line: 1, Raw: scanner.Text(), Err: err}}
+	}
+
+	inserted := int64(0)
+	importErrors := []ImportError{}
+	chunk := make([]Log, 0, chunkSize)
+	flush := func() {
+		if len(chunk) == 0 {
+			return
+		}
+		result := db.CreateInBatches(&chunk, chunkSize)
+		if err := WrapDBError(result); err != nil {
+			importErrors = append(importErrors, ImportError{Err: err})
+		}
+		inserted += result.RowsAffected
+		chunk = chunk[:0]
+	}
+
+	for line := 2; scanner.Scan(); line++ {
+		raw := scanner.Text()
+		record, err := parseCSVLine(raw)
+		if err == nil {
+			var log Log
+			if log, err = logFromCSV(record, mapping, positions); err == nil {
+				if chunk = append(chunk, log); len(chunk) == chunkSize {
+					flush()
+				}
+				continue
+			}
+		}
+		importErrors = append(importErrors, ImportError{Line: line, Raw: raw, Err: err})
+	}
+	flush()
+	if err := scanner.Err(); err != nil {
+		importErrors = append(importErrors, ImportError{Err: err})
+	}
+	return inserted, importErrors
+}
+
+func scannerErr(scanner *bufio.Scanner) error {
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.ErrUnexpectedEOF
+}
+
+// parseCSVLine parses a single line, so quoted fields can't span lines.
+func parseCSVLine(line string) ([]string, error) {
+	reader := csv.NewReader(strings.NewReader(line))
+	reader.FieldsPerRecord = -1
+	return reader.Read()
+}
+
+// headerMapping maps each header to the Log field of the same name, ignoring case.
+func headerMapping(header []string) []CSVMapping {
+	mapping := []CSVMapping{}
+	logType := reflect.TypeOf(Log{})
+	for _, name := range header {
+		name = strings.TrimSpace(name)
+		if f, ok := logType.FieldByNameFunc(func(field string) bool { return strings.EqualFold(field, name) }); ok {
+			mapping = append(mapping, CSVMapping{CSVColumn: name, LogField: f.Name})
+		}
+	}
+	return mapping
+}
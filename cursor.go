@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+// FindInCursor loads the logs matched by db in batches of bufSize and sends
+// them one by one on the first channel, which is buffered to bufSize. Every
+// *Log is a separate copy, so receivers may keep it.
+//
+// When loading stops, the error, if any, is sent on the second channel and
+// then both are closed. Cancelling ctx stops the goroutine even if nobody is
+// receiving any more; the error is then ctx.Err(). The error channel is
+// buffered, so it need not be read.
+func FindInCursor(ctx context.Context, db *gorm.DB, bufSize int) (<-chan *Log, <-chan error) {
+	if bufSize <= 0 {
+		bufSize = 1
+	}
+	logs := make(chan *Log, bufSize)
+	errs := make(chan error, 1)
+	go func() {
+		defer close(errs)
+		defer close(logs)
+
+		batch := []Log{}
+		var sendErr error
+		result := db.WithContext(ctx).FindInBatches(&batch, bufSize, func(tx *gorm.DB, _ int) error {
+			for i := range batch {
+				log := batch[i]
+				select {
+				case logs <- &log:
+				case <-ctx.Done():
+					sendErr = ctx.Err()
+					return sendErr
+				}
+			}
+			return nil
+		})
+		if sendErr != nil {
+			errs <- sendErr
+		} else if err := WrapDBError(result); err != nil {
+			errs <- err
+		}
+	}()
+	return logs, errs
+}
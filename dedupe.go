@@ -0,0 +1,35 @@
+package main
+
+import (
+	"database/sql"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DeduplicatingCreate inserts log unless a log with the same Msg was logged
+// in the last window, and reports whether it did. The check and the insert
+// share a serializable transaction so two callers can't both miss each
+// other's log. (The sqlite3 driver ignores the level, but SQLite transactions
+// are serializable anyway; a concurrent writer gets "database is locked".)
+func DeduplicatingCreate(db *gorm.DB, log *Log, window time.Duration) (created bool, err error) {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		existing := []Log{}
+		result := tx.Select("id").Where("msg = ? AND time > ?", log.Msg, time.Now().Add(-window)).Limit(1).Find(&existing)
+		if err := WrapDBError(result); err != nil {
+			return err
+		}
+		if result.RowsAffected > 0 {
+			return nil
+		}
+		if err := WrapDBError(tx.Create(log)); err != nil {
+			return err
+		}
+		created = true
+		return nil
+	}, &sql.TxOptions{Isolation: sql.LevelSerializable})
+	if err != nil {
+		return false, err
+	}
+	return created, nil
+}
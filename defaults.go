@@ -0,0 +1,26 @@
+package main
+
+import "reflect"
+
+// DefaultsRegistry maps a Log field name to a function returning its default.
+type DefaultsRegistry map[string]func() interface{}
+
+// LogDefaults is applied by the BeforeCreate hook. Fill it in at startup; it
+// isn't safe to change while logs are being created.
+var LogDefaults = DefaultsRegistry{}
+
+// ApplyDefaults sets every registered field that still has its zero value.
+func (l *Log) ApplyDefaults(reg DefaultsRegistry) {
+	v := reflect.ValueOf(l).Elem()
+	for name, fn := range reg {
+		field := v.FieldByName(name)
+		if !field.IsValid() || !field.CanSet() || !field.IsZero() {
+			continue
+		}
+		value := reflect.ValueOf(fn())
+		if !value.IsValid() || !value.Type().ConvertibleTo(field.Type()) {
+			continue
+		}
+		field.Set(value.Convert(field.Type()))
+	}
+}
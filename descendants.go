@@ -0,0 +1,21 @@
+package main
+
+import "gorm.io/gorm"
+
+// FindDescendants returns every log below rootID in the ParentID tree,
+// children first, then grandchildren and so on, with Depth set to 1, 2, ...
+func FindDescendants(db *gorm.DB, rootID uint) ([]Log, error) {
+	logs := []Log{}
+	err := WrapDBError(db.Raw(`
+		WITH RECURSIVE descendants(id, depth) AS (
+			SELECT id, 1 FROM logs WHERE parent_id = ? AND deleted_at IS NULL
+			UNION ALL
+			SELECT logs.id, descendants.depth + 1
+			FROM logs JOIN descendants ON logs.parent_id = descendants.id
+			WHERE logs.deleted_at IS NULL
+		)
+		SELECT logs.*, descendants.depth
+		FROM logs JOIN descendants ON logs.id = descendants.id
+		ORDER BY descendants.depth, logs.id`, rootID).Find(&logs))
+	return logs, err
+}
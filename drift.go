@@ -0,0 +1,64 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type FieldChange struct {
+	Field string
+	Old   interface{}
+	New   interface{}
+}
+
+// Diff lists the fields of Log that differ between old and new. Associations
+// such as LogDetails are not compared.
+func Diff(old, new Log) []FieldChange {
+	changes := []FieldChange{}
+	oldV, newV := reflect.ValueOf(old), reflect.ValueOf(new)
+	for i := 0; i < oldV.NumField(); i++ {
+		field := oldV.Type().Field(i)
+		if !field.IsExported() || field.Type.Kind() == reflect.Slice {
+			continue
+		}
+		o, n := oldV.Field(i).Interface(), newV.Field(i).Interface()
+		if !fieldEqual(o, n) {
+			changes = append(changes, FieldChange{Field: field.Name, Old: o, New: n})
+		}
+	}
+	return changes
+}
+
+// Times read back from the database lose their monotonic clock and location,
+// so they're compared with Equal.
+func fieldEqual(a, b interface{}) bool {
+	if at, ok := a.(time.Time); ok {
+		bt, ok := b.(time.Time)
+		return ok && at.Equal(bt)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// DetectDrift compares log with its current row in the database. A missing
+// row is not an error; it just has nothing to drift from.
+func DetectDrift(db *gorm.DB, log *Log) ([]FieldChange, error) {
+	changes := []FieldChange{}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		dbRow := Log{}
+		locking := clause.Locking{Strength: "SHARE"} // No effect on Sqlite.
+		err := WrapDBError(tx.Clauses(locking).First(&dbRow, log.ID))
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		changes = Diff(dbRow, *log)
+		return nil
+	})
+	return changes, err
+}
@@ -0,0 +1,47 @@
+package main
+
+import "gorm.io/gorm"
+
+const dryRunPrintKey = "dry_run:print"
+
+// WithDryRun returns a session that builds every statement without running
+// it. Results stay empty and RowsAffected stays 0. With DryRunPlugin in use,
+// each statement is logged too.
+func WithDryRun(db *gorm.DB) *gorm.DB {
+	return db.Set(dryRunPrintKey, true).Session(&gorm.Session{DryRun: true})
+}
+
+// DryRunPlugin logs the statements of WithDryRun sessions at Info level, with
+// their values inlined and then listed separately. Other dry runs, such as
+// ToSQL's, are left alone.
+type DryRunPlugin struct{}
+
+func (DryRunPlugin) Name() string {
+	return "dry_run:print"
+}
+
+func (p DryRunPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().After("*").Register(p.Name(), printDryRun),
+		cb.Query().After("*").Register(p.Name(), printDryRun),
+		cb.Update().After("*").Register(p.Name(), printDryRun),
+		cb.Delete().After("*").Register(p.Name(), printDryRun),
+		cb.Row().After("*").Register(p.Name(), printDryRun),
+		cb.Raw().After("*").Register(p.Name(), printDryRun),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printDryRun(tx *gorm.DB) {
+	stmt := tx.Statement
+	if _, ok := tx.Get(dryRunPrintKey); !ok || !stmt.DryRun || stmt.SQL.Len() == 0 {
+		return
+	}
+	sql := stmt.SQL.String()
+	tx.Logger.Info(stmt.Context, "dry run, not executed:\n\t%s\n\tvars: %v", tx.Dialector.Explain(sql, stmt.Vars...), stmt.Vars)
+}
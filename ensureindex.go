@@ -0,0 +1,16 @@
+package main
+
+import "gorm.io/gorm"
+
+// EnsureIndex creates the index declared on model as indexName unless it is
+// already there. created is false, with no error, when it already existed.
+func EnsureIndex(db *gorm.DB, model interface{}, indexName string) (created bool, err error) {
+	migrator := db.Migrator()
+	if migrator.HasIndex(model, indexName) {
+		return false, nil
+	}
+	if err := migrator.CreateIndex(model, indexName); err != nil {
+		return false, err
+	}
+	return true, nil
+}
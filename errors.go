@@ -0,0 +1,131 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// DBErrorCode classifies the cause of a DBError.
+type DBErrorCode int
+
+const (
+	DBErrorUnknown DBErrorCode = iota
+	DBErrorNotFound
+	DBErrorConstraint
+	DBErrorLocked
+	DBErrorMissingWhere
+)
+
+func (c DBErrorCode) String() string {
+	switch c {
+	case DBErrorNotFound:
+		return "not_found"
+	case DBErrorConstraint:
+		return "constraint"
+	case DBErrorLocked:
+		return "locked"
+	case DBErrorMissingWhere:
+		return "missing_where"
+	default:
+		return "unknown"
+	}
+}
+
+// DBError is result.Error plus what we know about the statement that caused it.
+type DBError struct {
+	Code         DBErrorCode
+	SQL          string
+	Model        string
+	Cause        error
+	RowsAffected int64
+}
+
+func (e *DBError) Error() string {
+	return fmt.Sprintf("%s on %s: %v (sql: %s)", e.Code, e.Model, e.Cause, e.SQL)
+}
+
+// Unwrap lets errors.Is and errors.As look at the Cause.
+func (e *DBError) Unwrap() error {
+	return e.Cause
+}
+
+// WrapDBError returns nil if the result has no error.
+func WrapDBError(result *gorm.DB) error {
+	if result.Error == nil {
+		return nil
+	}
+	sql := result.Statement.SQL.String()
+	if v, ok := result.InstanceGet(capturedSQLKey); ok && sql == "" {
+		sql = v.(string)
+	}
+	return &DBError{
+		Code:         dbErrorCode(result.Error),
+		SQL:          sql,
+		Model:        modelName(result.Statement.Model),
+		Cause:        result.Error,
+		RowsAffected: result.RowsAffected,
+	}
+}
+
+func dbErrorCode(err error) DBErrorCode {
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		return DBErrorNotFound
+	case errors.Is(err, gorm.ErrMissingWhereClause):
+		return DBErrorMissingWhere
+	case strings.Contains(err.Error(), "constraint failed"):
+		return DBErrorConstraint
+	case strings.Contains(err.Error(), "database is locked"):
+		return DBErrorLocked
+	default:
+		return DBErrorUnknown
+	}
+}
+
+// modelName gives "Log" for Log, *Log, []Log and *[]Log alike.
+func modelName(model interface{}) string {
+	if model == nil {
+		return ""
+	}
+	t := reflect.TypeOf(model)
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+const capturedSQLKey = "dberror:sql"
+
+// GORM resets Statement.SQL once the callbacks are done (unless DryRun), so
+// SQLCapturePlugin keeps a copy for WrapDBError.
+type SQLCapturePlugin struct{}
+
+func (SQLCapturePlugin) Name() string {
+	return "dberror:capture_sql"
+}
+
+func (p SQLCapturePlugin) Initialize(db *gorm.DB) error {
+	capture := func(tx *gorm.DB) {
+		if tx.Statement.SQL.Len() > 0 {
+			tx.InstanceSet(capturedSQLKey, tx.Dialector.Explain(tx.Statement.SQL.String(), tx.Statement.Vars...))
+		}
+	}
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().After("*").Register(p.Name(), capture),
+		cb.Query().After("*").Register(p.Name(), capture),
+		cb.Update().After("*").Register(p.Name(), capture),
+		cb.Delete().After("*").Register(p.Name(), capture),
+		cb.Row().After("*").Register(p.Name(), capture),
+		cb.Raw().After("*").Register(p.Name(), capture),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+type LogEvent struct {
+	EventType string // "created" or "updated"
+	LogID     uint
+	Payload   Log
+}
+
+type LogEventBus interface {
+	Publish(LogEvent) error
+}
+
+// LogEventsPlugin makes the AfterCreate and AfterUpdate hooks of Log publish
+// an event to Bus. Use it once at setup; the hooks of a db without it
+// publish nothing.
+type LogEventsPlugin struct {
+	Bus LogEventBus
+}
+
+func (LogEventsPlugin) Name() string {
+	return "log_events"
+}
+
+// Initialize has nothing to register: the hooks look the plugin up in
+// db.Config.Plugins, which db.Use has stored it in.
+func (LogEventsPlugin) Initialize(db *gorm.DB) error {
+	return nil
+}
+
+// publish hands the event to the bus of tx's LogEventsPlugin. Events go out
+// once the statement succeeds, before its transaction commits, so a
+// rolled-back log may still have been announced. A bus that fails is logged,
+// not returned: it mustn't stop the log from being written.
+func (u *Log) publish(tx *gorm.DB, eventType string) {
+	plugin, ok := tx.Config.Plugins[LogEventsPlugin{}.Name()].(LogEventsPlugin)
+	if !ok || plugin.Bus == nil || u.ID == 0 {
+		return
+	}
+	event := LogEvent{EventType: eventType, LogID: u.ID, Payload: *u}
+	if err := plugin.Bus.Publish(event); err != nil {
+		tx.Logger.Warn(tx.Statement.Context, "publishing %s event of log %d: %v", eventType, u.ID, err)
+	}
+}
+
+var ErrEventBusFull = errors.New("event bus full")
+
+// InMemoryEventBus buffers events in a channel for Events to read. Publish
+// never blocks: once the buffer is full it returns ErrEventBusFull.
+type InMemoryEventBus struct {
+	events chan LogEvent
+}
+
+func NewInMemoryEventBus(size int) *InMemoryEventBus {
+	return &InMemoryEventBus{events: make(chan LogEvent, size)}
+}
+
+func (b *InMemoryEventBus) Publish(event LogEvent) error {
+	select {
+	case b.events <- event:
+		return nil
+	default:
+		return ErrEventBusFull
+	}
+}
+
+func (b *InMemoryEventBus) Events() <-chan LogEvent {
+	return b.events
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"database/sql"
+
+	"gorm.io/gorm"
+)
+
+// Explain returns the query plan of db.Where(query, args...).Find(dest), one
+// line per row. db may be any chain, with Joins, Order and so on. The SQL is
+// rendered with ToSQL, so the values are inlined as literals.
+func Explain(db *gorm.DB, dest interface{}, query interface{}, args ...interface{}) ([]string, error) {
+	rendered := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		if query != nil {
+			tx = tx.Where(query, args...)
+		}
+		return tx.Find(dest)
+	})
+	return explainSQL(db, rendered)
+}
+
+// explainSQL returns the query plan of a rendered statement.
+func explainSQL(db *gorm.DB, rendered string) ([]string, error) {
+	prefix := "EXPLAIN "
+	switch db.Dialector.Name() {
+	case "sqlite":
+		prefix = "EXPLAIN QUERY PLAN "
+	case "postgres":
+		prefix = "EXPLAIN ANALYZE "
+	}
+	rows, err := db.Session(&gorm.Session{NewDB: true}).Raw(prefix + rendered).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	// The plan text is the last column: SQLite's "detail", Postgres' only one.
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	plan := []string{}
+	for rows.Next() {
+		values := make([]sql.RawBytes, len(columns))
+		dests := make([]interface{}, len(columns))
+		for i := range values {
+			dests[i] = &values[i]
+		}
+		if err := rows.Scan(dests...); err != nil {
+			return nil, err
+		}
+		plan = append(plan, string(values[len(values)-1]))
+	}
+	return plan, rows.Err()
+}
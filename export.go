@@ -0,0 +1,139 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/csv"
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const exportBatchSize = 500
+
+func init() {
+	// Metadata decoded from JSON nests these as interface{} values, which gob
+	// only encodes once their types are registered.
+	gob.Register(map[string]interface{}{})
+	gob.Register([]interface{}{})
+}
+
+type ExportFormat int
+
+const (
+	ExportCSV   ExportFormat = iota // a header of Log field names, then one row per log
+	ExportJSONL                     // one JSON object per line
+	ExportGob                       // one gob stream; decode Logs until io.EOF
+)
+
+// ExportLogs writes the logs matched by filter (all logs of db if it is nil)
+// to w, exportBatchSize at a time, and returns how many it wrote. Associations
+// such as LogDetails are not exported. filter is left as it was, so the same
+// one can be exported again.
+func ExportLogs(db *gorm.DB, w io.Writer, format ExportFormat, filter *gorm.DB) (int64, error) {
+	if filter == nil {
+		filter = db
+	}
+	filter = filter.Session(&gorm.Session{})
+	write, flush, err := exportWriter(w, format)
+	if err != nil {
+		return 0, err
+	}
+
+	written := int64(0)
+	batch := []Log{}
+	var writeErr error
+	result := filter.FindInBatches(&batch, exportBatchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			if writeErr = write(&batch[i]); writeErr != nil {
+				return writeErr
+			}
+			written++
+		}
+		return nil
+	})
+	if writeErr != nil {
+		return written, writeErr
+	}
+	if err := WrapDBError(result); err != nil {
+		return written, err
+	}
+	return written, flush()
+}
+
+func exportWriter(w io.Writer, format ExportFormat) (write func(*Log) error, flush func() error, err error) {
+	noFlush := func() error { return nil }
+	switch format {
+	case ExportCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(exportedLogFields()); err != nil {
+			return nil, nil, err
+		}
+		write = func(log *Log) error {
+			return cw.Write(csvRecord(log))
+		}
+		flush = func() error {
+			cw.Flush()
+			return cw.Error()
+		}
+		return write, flush, nil
+	case ExportJSONL:
+		enc := json.NewEncoder(w)
+		return func(log *Log) error { return enc.Encode(log) }, noFlush, nil
+	case ExportGob:
+		enc := gob.NewEncoder(w)
+		return func(log *Log) error { return enc.Encode(log) }, noFlush, nil
+	default:
+		return nil, nil, fmt.Errorf("ExportLogs: unknown format %d", format)
+	}
+}
+
+// exportedLogFields are the exported, non-association fields of Log.
+func exportedLogFields() []string {
+	names := []string{}
+	t := reflect.TypeOf(Log{})
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.IsExported() && field.Type.Kind() != reflect.Slice {
+			names = append(names, field.Name)
+		}
+	}
+	return names
+}
+
+// csvRecord formats the fields of exportedLogFields. Times are RFC 3339, as
+// ImportCSV expects, and NULLs are empty.
+func csvRecord(log *Log) []string {
+	v := reflect.ValueOf(log).Elem()
+	names := exportedLogFields()
+	record := make([]string, len(names))
+	for i, name := range names {
+		record[i] = csvValue(v.FieldByName(name).Interface())
+	}
+	return record
+}
+
+func csvValue(value interface{}) string {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil || v == nil {
+			return ""
+		}
+		value = v
+	}
+	switch v := value.(type) {
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case *uint:
+		if v == nil {
+			return ""
+		}
+		return strconv.FormatUint(uint64(*v), 10)
+	default:
+		return fmt.Sprint(v)
+	}
+}
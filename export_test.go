@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestExportGobRoundTripsNestedMetadata(t *testing.T) {
+	db, cleanup, err := InMemoryDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	log := Log{Time: time.Now(), Msg: "nested", Level: 1, Metadata: LogMetadata{
+		"request": map[string]interface{}{"path": "/login", "status": 500},
+		"tags":    []interface{}{"auth", "retry"},
+	}}
+	if err := db.Create(&log).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	out := bytes.Buffer{}
+	n, err := ExportLogs(db, &out, ExportGob, nil)
+	if err != nil || n != 1 {
+		t.Fatalf("got %d, %v; want 1, nil", n, err)
+	}
+	decoded := Log{}
+	if err := gob.NewDecoder(&out).Decode(&decoded); err != nil {
+		t.Fatal(err)
+	}
+	// Loaded back from JSON, the status is a float64.
+	want := LogMetadata{
+		"request": map[string]interface{}{"path": "/login", "status": float64(500)},
+		"tags":    []interface{}{"auth", "retry"},
+	}
+	if !reflect.DeepEqual(decoded.Metadata, want) {
+		t.Fatalf("got metadata %v; want %v", decoded.Metadata, want)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// FanOutFind runs Find(conds...) on every shard at once and puts the merged
+// logs into dest, sorted by Time. Each shard keeps its own context, so a
+// timeout set with WithContext applies to that shard. The first shard to fail
+// cancels the others, and that error is returned with dest left untouched.
+func FanOutFind(dbs []*gorm.DB, dest *[]Log, conds ...interface{}) error {
+	parts := make([][]Log, len(dbs))
+	cancels := make([]context.CancelFunc, len(dbs))
+	ctxs := make([]context.Context, len(dbs))
+	for i, db := range dbs {
+		ctx := db.Statement.Context
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		ctxs[i], cancels[i] = context.WithCancel(ctx)
+	}
+	cancelAll := func() {
+		for _, cancel := range cancels {
+			cancel()
+		}
+	}
+	defer cancelAll()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	for i, db := range dbs {
+		wg.Add(1)
+		go func(i int, db *gorm.DB) {
+			defer wg.Done()
+			err := WrapDBError(db.WithContext(ctxs[i]).Find(&parts[i], conds...))
+			if err == nil || errors.Is(err, gorm.ErrRecordNotFound) {
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if firstErr == nil {
+				firstErr = err
+				cancelAll()
+			}
+		}(i, db)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+
+	merged := []Log{}
+	for _, part := range parts {
+		merged = append(merged, part...)
+	}
+	sort.SliceStable(merged, func(i, j int) bool { return merged[i].Time.Before(merged[j].Time) })
+	*dest = merged
+	return nil
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CompileFilter turns a filter expression into a scope. The grammar is:
+//
+//	expr       = and { "OR" and }
+//	and        = unary { "AND" unary }
+//	unary      = "NOT" unary | "(" expr ")" | comparison
+//	comparison = column op value | column "IN" "(" value { "," value } ")"
+//	op         = "=" | "!=" | ">" | ">=" | "<" | "<=" | "~"
+//
+// where ~ is LIKE, values are numbers or quoted strings, and keywords are
+// case-insensitive. For example: level>=3 AND (msg~'%error%' OR msg IN ('a','b')).
+func CompileFilter(expr string) (func(*gorm.DB) *gorm.DB, error) {
+	tokens, err := lexFilter(expr)
+	if err != nil {
+		return nil, err
+	}
+	p := filterParser{tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		return nil, fmt.Errorf("filter: unexpected %q at %d", tok.text, tok.pos)
+	}
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(node.group(db))
+	}, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokIdent
+	tokNumber
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+	tokComma
+)
+
+type filterToken struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func lexFilter(expr string) ([]filterToken, error) {
+	tokens := []filterToken{}
+	runes := []rune(expr)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, filterToken{tokLParen, "(", i})
+			i++
+		case r == ')':
+			tokens = append(tokens, filterToken{tokRParen, ")", i})
+			i++
+		case r == ',':
+			tokens = append(tokens, filterToken{tokComma, ",", i})
+			i++
+		case r == '\'' || r == '"':
+			end := i + 1
+			for end < len(runes) && runes[end] != r {
+				end++
+			}
+			if end == len(runes) {
+				return nil, fmt.Errorf("filter: unterminated string at %d", i)
+			}
+			tokens = append(tokens, filterToken{tokString, string(runes[i+1 : end]), i})
+			i = end + 1
+		case strings.ContainsRune("=!<>~", r):
+			op := string(r)
+			if i+1 < len(runes) && runes[i+1] == '=' && r != '=' && r != '~' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, fmt.Errorf("filter: expected != at %d", i)
+			}
+			tokens = append(tokens, filterToken{tokOp, op, i})
+			i += len(op)
+		case unicode.IsDigit(r) || r == '-' || r == '.':
+			end := i + 1
+			for end < len(runes) && (unicode.IsDigit(runes[end]) || runes[end] == '.') {
+				end++
+			}
+			tokens = append(tokens, filterToken{tokNumber, string(runes[i:end]), i})
+			i = end
+		case unicode.IsLetter(r) || r == '_':
+			end := i + 1
+			for end < len(runes) && (unicode.IsLetter(runes[end]) || unicode.IsDigit(runes[end]) || runes[end] == '_') {
+				end++
+			}
+			tokens = append(tokens, filterToken{tokIdent, string(runes[i:end]), i})
+			i = end
+		default:
+			return nil, fmt.Errorf("filter: unexpected %q at %d", r, i)
+		}
+	}
+	return append(tokens, filterToken{tokEOF, "", len(runes)}), nil
+}
+
+// filterNode builds itself as a GORM group condition on a fresh session.
+type filterNode interface {
+	group(db *gorm.DB) *gorm.DB
+}
+
+type filterAnd []filterNode
+type filterOr []filterNode
+type filterNot struct{ node filterNode }
+type filterCmp struct{ expr clause.Expression }
+
+func newGroup(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{NewDB: true})
+}
+
+func (n filterAnd) group(db *gorm.DB) *gorm.DB {
+	tx := newGroup(db)
+	for _, child := range n {
+		tx = tx.Where(child.group(db))
+	}
+	return tx
+}
+
+func (n filterOr) group(db *gorm.DB) *gorm.DB {
+	tx := newGroup(db).Where(n[0].group(db))
+	for _, child := range n[1:] {
+		tx = tx.Or(child.group(db))
+	}
+	return tx
+}
+
+func (n filterNot) group(db *gorm.DB) *gorm.DB {
+	return newGroup(db).Not(n.node.group(db))
+}
+
+func (n filterCmp) group(db *gorm.DB) *gorm.DB {
+	return newGroup(db).Where(n.expr)
+}
+
+type filterParser struct {
+	tokens []filterToken
+	pos    int
+}
+
+func (p *filterParser) peek() filterToken {
+	return p.tokens[p.pos]
+}
+
+func (p *filterParser) next() filterToken {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *filterParser) keyword(word string) bool {
+	if tok := p.peek(); tok.kind == tokIdent && strings.EqualFold(tok.text, word) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *filterParser) expect(kind tokenKind, what string) (filterToken, error) {
+	tok := p.next()
+	if tok.kind != kind {
+		return tok, fmt.Errorf("filter: expected %s at %d, got %q", what, tok.pos, tok.text)
+	}
+	return tok, nil
+}
+
+func (p *filterParser) parseOr() (filterNode, error) {
+	node, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	nodes := filterOr{node}
+	for p.keyword("OR") {
+		if node, err = p.parseAnd(); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *filterParser) parseAnd() (filterNode, error) {
+	node, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	nodes := filterAnd{node}
+	for p.keyword("AND") {
+		if node, err = p.parseUnary(); err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, node)
+	}
+	if len(nodes) == 1 {
+		return nodes[0], nil
+	}
+	return nodes, nil
+}
+
+func (p *filterParser) parseUnary() (filterNode, error) {
+	if p.keyword("NOT") {
+		node, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return filterNot{node}, nil
+	}
+	if p.peek().kind == tokLParen {
+		p.next()
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokRParen, ")"); err != nil {
+			return nil, err
+		}
+		return node, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (filterNode, error) {
+	ident, err := p.expect(tokIdent, "column")
+	if err != nil {
+		return nil, err
+	}
+	column := clause.Column{Name: ident.text}
+
+	if p.keyword("IN") {
+		if _, err := p.expect(tokLParen, "("); err != nil {
+			return nil, err
+		}
+		values := []interface{}{}
+		for {
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+			if p.peek().kind != tokComma {
+				break
+			}
+			p.next()
+		}
+		if _, err := p.expect(tokRParen, ")"); err != nil {
+			return nil, err
+		}
+		return filterCmp{clause.IN{Column: column, Values: values}}, nil
+	}
+
+	op, err := p.expect(tokOp, "operator")
+	if err != nil {
+		return nil, err
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	switch op.text {
+	case "=":
+		return filterCmp{clause.Eq{Column: column, Value: value}}, nil
+	case "!=":
+		return filterCmp{clause.Neq{Column: column, Value: value}}, nil
+	case ">":
+		return filterCmp{clause.Gt{Column: column, Value: value}}, nil
+	case ">=":
+		return filterCmp{clause.Gte{Column: column, Value: value}}, nil
+	case "<":
+		return filterCmp{clause.Lt{Column: column, Value: value}}, nil
+	case "<=":
+		return filterCmp{clause.Lte{Column: column, Value: value}}, nil
+	default: // "~"
+		return filterCmp{clause.Like{Column: column, Value: value}}, nil
+	}
+}
+
+func (p *filterParser) parseValue() (interface{}, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokString:
+		return tok.text, nil
+	case tokNumber:
+		if n, err := strconv.ParseInt(tok.text, 10, 64); err == nil {
+			return n, nil
+		}
+		f, err := strconv.ParseFloat(tok.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("filter: bad number %q at %d", tok.text, tok.pos)
+		}
+		return f, nil
+	default:
+		return nil, fmt.Errorf("filter: expected value at %d, got %q", tok.pos, tok.text)
+	}
+}
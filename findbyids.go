@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrEmptyIDSlice = errors.New("no ids given")
+
+// FindByIDs loads the records of T whose primary key is in ids, which must be
+// a non-empty []uint, []int or []string. model only names T, as in
+// FindByIDs(db, &LogEntry{}, []string{...}); it isn't read.
+func FindByIDs[T any](db *gorm.DB, model *T, ids interface{}) ([]T, error) {
+	values := []interface{}{}
+	switch ids := ids.(type) {
+	case []uint:
+		for _, id := range ids {
+			values = append(values, id)
+		}
+	case []int:
+		for _, id := range ids {
+			values = append(values, id)
+		}
+	case []string:
+		for _, id := range ids {
+			values = append(values, id)
+		}
+	default:
+		return nil, fmt.Errorf("FindByIDs: ids must be []uint, []int or []string, not %T", ids)
+	}
+	if len(values) == 0 {
+		return nil, ErrEmptyIDSlice
+	}
+
+	records := []T{}
+	err := WrapDBError(db.Where(clause.IN{Column: clause.PrimaryColumn, Values: values}).Find(&records))
+	return records, err
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Flatten copies each of the details with the parent Msg prefixed to DetailMsg.
+func (l Log) Flatten() []LogDetail {
+	details := make([]LogDetail, 0, len(l.LogDetails))
+	for _, ld := range l.LogDetails {
+		ld.DetailMsg = fmt.Sprintf("[%s] %s", l.Msg, ld.DetailMsg)
+		details = append(details, ld)
+	}
+	return details
+}
+
+// FlattenAll flattens the details of every log into a single slice.
+func FlattenAll(db *gorm.DB) ([]LogDetail, error) {
+	logs := []Log{}
+	if err := WrapDBError(db.Preload("LogDetails").Find(&logs)); err != nil {
+		return nil, err
+	}
+	details := []LogDetail{}
+	for _, l := range logs {
+		details = append(details, l.Flatten()...)
+	}
+	return details, nil
+}
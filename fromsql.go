@@ -0,0 +1,16 @@
+package main
+
+import "gorm.io/gorm"
+
+// FromSQL selects from rawSQL as a subquery named subq, so the result can be
+// filtered, ordered and limited with the usual chain:
+//
+//	FromSQL(db, "SELECT level, count(*) AS total FROM logs GROUP BY level").
+//		Where("total > ?", 10).Order("total DESC").Find(&rows)
+//
+// The subquery starts from a fresh session, so conditions already on db apply
+// to the outer query only.
+func FromSQL(db *gorm.DB, rawSQL string, args ...interface{}) *gorm.DB {
+	subquery := db.Session(&gorm.Session{NewDB: true}).Raw(rawSQL, args...)
+	return db.Table("(?) AS subq", subquery)
+}
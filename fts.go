@@ -0,0 +1,69 @@
+//go:build sqlite_fts5
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SetupFTS creates logs_fts, an FTS5 index of logs.msg, and the triggers that
+// keep it in step with logs. It is safe to run again; the index is only
+// filled from the existing rows when it is first created.
+//
+// The sqlite3 driver only has FTS5 when built with -tags sqlite_fts5. Once
+// the triggers exist every write to logs needs it, so only run this on a
+// database that builds without the tag never open.
+func SetupFTS(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		exists := tx.Migrator().HasTable("logs_fts")
+		for _, ddl := range []string{
+			`CREATE VIRTUAL TABLE IF NOT EXISTS logs_fts USING fts5(msg, content='logs', content_rowid='id')`,
+			`CREATE TRIGGER IF NOT EXISTS logs_fts_insert AFTER INSERT ON logs BEGIN
+				INSERT INTO logs_fts(rowid, msg) VALUES (new.id, new.msg);
+			END`,
+			`CREATE TRIGGER IF NOT EXISTS logs_fts_delete AFTER DELETE ON logs BEGIN
+				INSERT INTO logs_fts(logs_fts, rowid, msg) VALUES ('delete', old.id, old.msg);
+			END`,
+			`CREATE TRIGGER IF NOT EXISTS logs_fts_update AFTER UPDATE OF msg ON logs BEGIN
+				INSERT INTO logs_fts(logs_fts, rowid, msg) VALUES ('delete', old.id, old.msg);
+				INSERT INTO logs_fts(rowid, msg) VALUES (new.id, new.msg);
+			END`,
+		} {
+			if err := WrapDBError(tx.Exec(ddl)); err != nil {
+				return err
+			}
+		}
+		if exists {
+			return nil
+		}
+		return WrapDBError(tx.Exec(`INSERT INTO logs_fts(logs_fts) VALUES ('rebuild')`))
+	})
+}
+
+// CREATE VIRTUAL TABLE logs_fts ... and its triggers (on a scratch database)
+// SELECT logs.* FROM `logs` JOIN logs_fts ON logs_fts.rowid = logs.id
+// WHERE logs_fts MATCH "outage" AND `logs`.`deleted_at` IS NULL ORDER BY logs_fts.rank
+func fullTextSearchDemo() {
+	db := newScratchDB("fts", &Log{}, &LogDetail{})
+	if err := SetupFTS(db); err != nil {
+		fmt.Println(err)
+		return
+	}
+	for _, msg := range []string{"outage in eu-west", "outage outage: database outage", "all quiet"} {
+		if err := WrapDBError(db.Create(&Log{Time: time.Now(), Msg: msg})); err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	logs, err := NewLogRepository(db).Search(context.Background(), "outage")
+	if err != nil {
+		fmt.Println(err)
+	}
+	for _, log := range logs {
+		fmt.Println(log.Msg) // The one saying outage three times first.
+	}
+}
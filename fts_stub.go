@@ -0,0 +1,6 @@
+//go:build !sqlite_fts5
+
+package main
+
+// Build with -tags sqlite_fts5 to run the full-text search demo.
+func fullTextSearchDemo() {}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+// GetOrCreate loads the log with the same Msg and Level into l, or inserts l
+// if there is none. A map is used for the lookup so that Level 0 still counts.
+//
+// If someone else inserts the same log between the lookup and the insert,
+// the unique idx_msg_level index rejects the insert and their row is loaded
+// instead.
+func (l *Log) GetOrCreate(db *gorm.DB) (created bool, err error) {
+	l.ID = 0 // Otherwise First would look the stale ID up too.
+	key := map[string]interface{}{"msg": l.Msg, "level": l.Level}
+	result := db.Where(key).FirstOrCreate(l)
+	err = WrapDBError(result)
+	var dbErr *DBError
+	if errors.As(err, &dbErr) && dbErr.Code == DBErrorConstraint {
+		l.ID = 0
+		if WrapDBError(db.Where(key).First(l)) == nil {
+			return false, nil
+		}
+	}
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestGetOrCreateLoadsTheRowThatWonTheRace(t *testing.T) {
+	db, cleanup, err := InMemoryDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	// Someone else inserts the same log between GetOrCreate's lookup and insert.
+	rival := Log{Time: time.Now(), Msg: "raced", Level: 4}
+	raced := false
+	err = db.Callback().Create().Before("gorm:create").Register("test:race", func(tx *gorm.DB) {
+		if raced {
+			return
+		}
+		raced = true
+		if err := tx.Session(&gorm.Session{NewDB: true}).Create(&rival).Error; err != nil {
+			t.Error(err)
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without the default transaction, or the failed insert would roll the
+	// rival back with it.
+	db = db.Session(&gorm.Session{SkipDefaultTransaction: true})
+	log := Log{Time: time.Now(), Msg: "raced", Level: 4}
+	created, err := log.GetOrCreate(db)
+	if err != nil || created {
+		t.Fatalf("got created %v, err %v; want false, nil", created, err)
+	}
+	if log.ID != rival.ID {
+		t.Errorf("loaded log %d, want the rival's %d", log.ID, rival.ID)
+	}
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// GroupByMsg groups the details of a log by the first word of DetailMsg, in
+// Go rather than with GROUP BY.
+func GroupByMsg(db *gorm.DB, logID uint) (map[string][]LogDetail, error) {
+	details := []LogDetail{}
+	if err := WrapDBError(db.Where("log_id = ?", logID).Find(&details)); err != nil {
+		return nil, err
+	}
+	groups := map[string][]LogDetail{}
+	for _, d := range details {
+		key := ""
+		if words := strings.Fields(d.DetailMsg); len(words) > 0 {
+			key = words[0]
+		}
+		groups[key] = append(groups[key], d)
+	}
+	return groups, nil
+}
+
+func SortedKeys(m map[string][]LogDetail) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
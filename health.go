@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type healthConfig struct {
+	timeout time.Duration
+}
+
+type HealthOption func(*healthConfig)
+
+// WithHealthTimeout sets how long HealthCheck waits for the database (default 2s).
+func WithHealthTimeout(d time.Duration) HealthOption {
+	return func(c *healthConfig) {
+		c.timeout = d
+	}
+}
+
+// HealthCheck runs SELECT 1, for liveness and readiness probes.
+func HealthCheck(db *gorm.DB, opts ...HealthOption) error {
+	config := healthConfig{timeout: 2 * time.Second}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), config.timeout)
+	defer cancel()
+
+	start := time.Now()
+	if err := db.WithContext(ctx).Exec("SELECT 1").Error; err != nil {
+		return fmt.Errorf("database unhealthy after %s: %w", time.Since(start).Round(time.Millisecond), err)
+	}
+	return nil
+}
+
+// HealthStats returns the connection pool stats of db's *sql.DB.
+func HealthStats(db *gorm.DB) (sql.DBStats, error) {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return sql.DBStats{}, err
+	}
+	return sqlDB.Stats(), nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var ErrImmutableRecord = errors.New("records are append-only")
+
+type immutableBypassKey struct{}
+
+// ImmutablePlugin turns every UPDATE and DELETE into ErrImmutableRecord,
+// except on sessions from maintenanceBypass.
+type ImmutablePlugin struct{}
+
+func (ImmutablePlugin) Name() string {
+	return "immutable"
+}
+
+func (ImmutablePlugin) Initialize(db *gorm.DB) error {
+	reject := func(tx *gorm.DB) {
+		if bypass, _ := tx.Statement.Context.Value(immutableBypassKey{}).(bool); !bypass {
+			tx.AddError(ErrImmutableRecord)
+		}
+	}
+	if err := db.Callback().Update().Before("gorm:before_update").Register("immutable:reject", reject); err != nil {
+		return err
+	}
+	return db.Callback().Delete().Before("gorm:before_delete").Register("immutable:reject", reject)
+}
+
+// maintenanceBypass is for admin-only fixes; it is unexported on purpose.
+func maintenanceBypass(db *gorm.DB) *gorm.DB {
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return db.WithContext(context.WithValue(ctx, immutableBypassKey{}, true))
+}
@@ -0,0 +1,29 @@
+package main
+
+import "regexp"
+
+var placeholderPattern = regexp.MustCompile(`\{(\w+)\}`)
+
+// Interpolate replaces each {key} in Msg with vars[key]. Unknown keys are left as they are.
+func (l Log) Interpolate(vars map[string]string) string {
+	return placeholderPattern.ReplaceAllStringFunc(l.Msg, func(placeholder string) string {
+		if value, ok := vars[placeholder[1:len(placeholder)-1]]; ok {
+			return value
+		}
+		return placeholder
+	})
+}
+
+// ValidatePlaceholders lists the placeholder keys in msg that vars lacks, in order, once each.
+func ValidatePlaceholders(msg string, vars map[string]string) []string {
+	missing := []string{}
+	seen := map[string]bool{}
+	for _, match := range placeholderPattern.FindAllStringSubmatch(msg, -1) {
+		key := match[1]
+		if _, ok := vars[key]; !ok && !seen[key] {
+			seen[key] = true
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
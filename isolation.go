@@ -0,0 +1,51 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// SetIsolationLevel begins a transaction with the given isolation level.
+func SetIsolationLevel(db *gorm.DB, level sql.IsolationLevel) (*gorm.DB, error) {
+	tx := db.Begin(&sql.TxOptions{Isolation: level})
+	return tx, tx.Error
+}
+
+// DemoDirtyRead has T1 update the first log without committing while T2
+// reads it with READ UNCOMMITTED. MySQL lets T2 see T1's msg; PostgreSQL
+// treats READ UNCOMMITTED as READ COMMITTED, and the sqlite3 driver ignores
+// the level, so both of those show the committed msg.
+func DemoDirtyRead(db *gorm.DB) error {
+	log := Log{}
+	if err := WrapDBError(db.First(&log)); err != nil {
+		return err
+	}
+
+	t1 := db.Begin()
+	if t1.Error != nil {
+		return t1.Error
+	}
+	defer t1.Rollback()
+	if err := WrapDBError(t1.Model(&Log{}).Where("id = ?", log.ID).Update("msg", "uncommitted!")); err != nil {
+		return err
+	}
+
+	t2, err := SetIsolationLevel(db, sql.LevelReadUncommitted)
+	if err != nil {
+		return err
+	}
+	defer t2.Rollback()
+	seen := Log{}
+	if err := WrapDBError(t2.First(&seen, log.ID)); err != nil {
+		return err
+	}
+
+	if seen.Msg == log.Msg {
+		fmt.Println("clean read:", seen.Msg)
+	} else {
+		fmt.Println("dirty read:", seen.Msg)
+	}
+	return nil
+}
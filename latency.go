@@ -0,0 +1,87 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const latencyWindowSize = 1000
+
+// LatencyAlertPlugin calls AlertFn whenever the P99 of the last 1000 inserts
+// into a table goes over Threshold.
+type LatencyAlertPlugin struct {
+	Threshold time.Duration
+	AlertFn   func(table string, p99 time.Duration)
+
+	mu      sync.Mutex
+	windows map[string]*latencyWindow
+}
+
+// latencyWindow is a ring buffer of the most recent durations.
+type latencyWindow struct {
+	durations [latencyWindowSize]time.Duration
+	next      int
+	full      bool
+}
+
+func (w *latencyWindow) add(d time.Duration) {
+	w.durations[w.next] = d
+	w.next = (w.next + 1) % latencyWindowSize
+	if w.next == 0 {
+		w.full = true
+	}
+}
+
+func (w *latencyWindow) p99() time.Duration {
+	n := w.next
+	if w.full {
+		n = latencyWindowSize
+	}
+	if n == 0 {
+		return 0
+	}
+	sorted := make([]time.Duration, n)
+	copy(sorted, w.durations[:n])
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	return sorted[(n*99-1)/100]
+}
+
+func (p *LatencyAlertPlugin) Name() string {
+	return "latency_alert"
+}
+
+func (p *LatencyAlertPlugin) Initialize(db *gorm.DB) error {
+	p.windows = map[string]*latencyWindow{}
+	err := db.Callback().Create().Before("gorm:before_create").Register("latency_alert:start", func(tx *gorm.DB) {
+		tx.InstanceSet("latency_alert:start", time.Now())
+	})
+	if err != nil {
+		return err
+	}
+	return db.Callback().Create().After("gorm:after_create").Register("latency_alert:end", p.record)
+}
+
+func (p *LatencyAlertPlugin) record(tx *gorm.DB) {
+	start, ok := tx.InstanceGet("latency_alert:start")
+	if !ok || tx.Error != nil {
+		return
+	}
+	table := tx.Statement.Table
+
+	p.mu.Lock()
+	w, ok := p.windows[table]
+	if !ok {
+		w = &latencyWindow{}
+		p.windows[table] = w
+	}
+	w.add(time.Since(start.(time.Time)))
+	p99 := w.p99()
+	p.mu.Unlock()
+
+	if p99 > p.Threshold && p.AlertFn != nil {
+		p.AlertFn(table, p99)
+	}
+}
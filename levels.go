@@ -0,0 +1,27 @@
+package main
+
+import "strconv"
+
+// Log levels. Anything above LevelFatal is still allowed, it just has no name.
+const (
+	LevelDebug int8 = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+	LevelFatal
+)
+
+var levelNames = map[int8]string{
+	LevelDebug: "DEBUG",
+	LevelInfo:  "INFO",
+	LevelWarn:  "WARN",
+	LevelError: "ERROR",
+	LevelFatal: "FATAL",
+}
+
+func LevelName(level int8) string {
+	if name, ok := levelNames[level]; ok {
+		return name
+	}
+	return "LEVEL" + strconv.Itoa(int(level))
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// LogEntry is Log with a random UUID primary key instead of an auto-increment
+// one, so IDs can be made up without asking the database.
+type LogEntry struct {
+	ID    string `gorm:"primaryKey;type:char(36)"`
+	Time  time.Time
+	Msg   string
+	Level int8
+}
+
+// BeforeCreate keeps an ID set by the caller, which upserts rely on.
+func (e *LogEntry) BeforeCreate(tx *gorm.DB) (err error) {
+	if e.ID == "" {
+		e.ID, err = newUUID()
+	}
+	return err
+}
+
+// newUUID returns a version 4 UUID such as 0f8fad5b-d9cb-469f-a165-70867728950e.
+func newUUID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = b[6]&0x0f | 0x40 // Version 4.
+	b[8] = b[8]&0x3f | 0x80 // RFC 4122 variant.
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
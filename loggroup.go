@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+var ErrGroupCycle = errors.New("a group cannot move below itself")
+
+// LogGroup is a tree of groups. Path lists the IDs from the root down to the
+// group itself, e.g. "1/4/7", so a subtree is a single prefix match.
+type LogGroup struct {
+	ID       uint
+	ParentID *uint  `gorm:"index"`
+	Path     string `gorm:"index"`
+	Name     string
+}
+
+// BeforeCreate sets Path to the IDs of g's ancestors. g's own ID is only known
+// after the insert, so AfterCreate appends it.
+func (g *LogGroup) BeforeCreate(tx *gorm.DB) (err error) {
+	ids := []string{}
+	for parentID := g.ParentID; parentID != nil; {
+		parent := LogGroup{}
+		if err := tx.Select("id", "parent_id").First(&parent, *parentID).Error; err != nil {
+			return err
+		}
+		ids = append([]string{strconv.FormatUint(uint64(parent.ID), 10)}, ids...)
+		parentID = parent.ParentID
+	}
+	g.Path = strings.Join(ids, "/")
+	return nil
+}
+
+func (g *LogGroup) AfterCreate(tx *gorm.DB) (err error) {
+	g.Path = joinGroupPath(g.Path, g.ID)
+	return tx.Model(g).UpdateColumn("path", g.Path).Error
+}
+
+func joinGroupPath(parentPath string, id uint) string {
+	if parentPath == "" {
+		return strconv.FormatUint(uint64(id), 10)
+	}
+	return parentPath + "/" + strconv.FormatUint(uint64(id), 10)
+}
+
+// FindSubtree returns the group and everything below it, ordered by Path.
+func FindSubtree(db *gorm.DB, groupID uint) ([]LogGroup, error) {
+	root := LogGroup{}
+	if err := WrapDBError(db.First(&root, groupID)); err != nil {
+		return nil, err
+	}
+	groups := []LogGroup{}
+	err := WrapDBError(db.Where("path = ? OR path LIKE ?", root.Path, root.Path+"/%").Order("path").Find(&groups))
+	return groups, err
+}
+
+// MoveGroup puts the group under newParentID, or at the root if it is 0, and
+// rewrites the paths of the group and all its descendants in one transaction.
+// Moving a group below one of its own descendants is ErrGroupCycle.
+func MoveGroup(db *gorm.DB, groupID, newParentID uint) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		group := LogGroup{}
+		if err := WrapDBError(tx.First(&group, groupID)); err != nil {
+			return err
+		}
+		var parentID *uint
+		newPath := joinGroupPath("", group.ID)
+		if newParentID != 0 {
+			parent := LogGroup{}
+			if err := WrapDBError(tx.First(&parent, newParentID)); err != nil {
+				return err
+			}
+			if parent.Path == group.Path || strings.HasPrefix(parent.Path, group.Path+"/") {
+				return ErrGroupCycle
+			}
+			parentID = &parent.ID
+			newPath = joinGroupPath(parent.Path, group.ID)
+		}
+
+		if err := WrapDBError(tx.Model(&group).UpdateColumn("parent_id", parentID)); err != nil {
+			return err
+		}
+		// Swap the old prefix for the new one; the rest of each path stays.
+		return WrapDBError(tx.Model(&LogGroup{}).
+			Where("path = ? OR path LIKE ?", group.Path, group.Path+"/%").
+			UpdateColumn("path", gorm.Expr("? || substr(path, ?)", newPath, len(group.Path)+1)))
+	})
+}
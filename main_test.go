@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAfterFindNormalisesWithoutWritingBack(t *testing.T) {
+	db, cleanup, err := InMemoryDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	log := Log{Time: time.Now(), Msg: "  Disk FULL on /var  "}
+	if err := db.Create(&log).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := Log{}
+	if err := db.First(&loaded, log.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Msg != "disk full on /var" {
+		t.Errorf("First gave %q", loaded.Msg)
+	}
+	scanned := []Log{}
+	if err := db.Raw("SELECT * FROM logs WHERE id = ?", log.ID).Scan(&scanned).Error; err != nil {
+		t.Fatal(err)
+	}
+	NormaliseMsgs(scanned)
+	if scanned[0].Msg != "disk full on /var" {
+		t.Errorf("Scan and NormaliseMsgs gave %q", scanned[0].Msg)
+	}
+
+	loaded.Level = 3
+	if err := db.Save(&loaded).Error; err != nil {
+		t.Fatal(err)
+	}
+	stored := ""
+	if err := db.Raw("SELECT msg FROM logs WHERE id = ?", log.ID).Scan(&stored).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored != log.Msg {
+		t.Errorf("Save wrote %q, want the stored %q", stored, log.Msg)
+	}
+	changed := int64(0)
+	db.Model(&ChangeLog{}).Where("record_id = ? AND field_name = ?", log.ID, "Msg").Count(&changed)
+	if changed != 0 {
+		t.Errorf("recorded %d Msg changes for a Msg that wasn't written", changed)
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+var markdownEscaper = strings.NewReplacer("|", `\|`, "\r\n", " ", "\n", " ")
+
+// ToMarkdown formats l as a row of the LogsToMarkdownTable table.
+func (l Log) ToMarkdown() string {
+	return fmt.Sprintf("| %d | %s | %s | %s |",
+		l.ID, l.Time.Format(time.RFC3339), LevelName(l.Level), markdownEscaper.Replace(l.Msg))
+}
+
+func LogsToMarkdownTable(logs []Log) string {
+	b := strings.Builder{}
+	b.WriteString("| ID | Time | Level | Msg |\n")
+	b.WriteString("| --- | --- | --- | --- |\n")
+	for _, l := range logs {
+		b.WriteString(l.ToMarkdown())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
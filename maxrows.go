@@ -0,0 +1,39 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+var ErrResultSetTooLarge = errors.New("result set too large")
+
+// MaxRowsPlugin fails every query that returns more than limit rows with
+// ErrResultSetTooLarge, so a Find missing its Limit is noticed instead of
+// silently truncated. AfterFind hooks don't run for the failed query, but the
+// rows have already been read into dest: it catches runaway queries, it
+// doesn't save their memory.
+//
+// MaxPageSizePlugin is the cheaper alternative: it adds a LIMIT before the
+// query runs, so no more than the cap is ever read, but a caller who needed
+// every row gets the first page without being told.
+func MaxRowsPlugin(limit int) gorm.Plugin {
+	return maxRowsPlugin{limit: limit}
+}
+
+type maxRowsPlugin struct {
+	limit int
+}
+
+func (p maxRowsPlugin) Name() string {
+	return "max_rows"
+}
+
+func (p maxRowsPlugin) Initialize(db *gorm.DB) error {
+	return db.Callback().Query().After("gorm:query").Register("max_rows:check", func(tx *gorm.DB) {
+		if tx.Error == nil && tx.Statement.RowsAffected > int64(p.limit) {
+			tx.AddError(fmt.Errorf("%w: %d rows, limit %d", ErrResultSetTooLarge, tx.Statement.RowsAffected, p.limit))
+		}
+	})
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// appModels are the tables the app migrates on start.
+var appModels = []interface{}{
+	&Log{}, &LogDetail{}, &Tag{}, &LogEntry{}, &ChangeLog{}, &LogAlertRule{}, &Translation{}, &LogGroup{},
+}
+
+var memoryDBs int64
+
+// InMemoryDB opens a migrated in-memory SQLite database for a test, and a
+// cleanup func that closes it. Every call gets a database of its own: a plain
+// file::memory:?cache=shared would be one database for the whole process, so
+// each is given a unique name. It is gone once cleanup closes it; cleanup
+// panics if the connection doesn't close.
+func InMemoryDB() (*gorm.DB, func(), error) {
+	name := fmt.Sprintf("file:memdb%d?mode=memory&cache=shared", atomic.AddInt64(&memoryDBs, 1))
+	db, err := gorm.Open(sqlite.Open(name), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Warn),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, nil, err
+	}
+	sqlDB.SetMaxOpenConns(1) // Shared-cache writers would otherwise lock each other out.
+	cleanup := func() {
+		if err := sqlDB.Close(); err != nil {
+			panic(fmt.Sprintf("InMemoryDB: close: %v", err))
+		}
+		if sqlDB.Ping() == nil {
+			panic("InMemoryDB: database still open after close")
+		}
+	}
+	if err := db.AutoMigrate(appModels...); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	return db, cleanup, nil
+}
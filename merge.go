@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Merge returns a new, unsaved Log combining both messages and the higher level.
+func (l Log) Merge(other Log) Log {
+	level := l.Level
+	if other.Level > level {
+		level = other.Level
+	}
+	return Log{
+		Time:  time.Now(),
+		Msg:   l.Msg + " | " + other.Msg,
+		Level: level,
+	}
+}
+
+// MergeAndSave merges the logs in the order of ids and inserts the result. It
+// takes at least two ids: a single log would be inserted again as it is, which
+// the unique indexes on its msg reject.
+func MergeAndSave(db *gorm.DB, ids []uint) (*Log, error) {
+	if len(ids) < 2 {
+		return nil, errors.New("MergeAndSave: need at least two log ids to merge")
+	}
+	logs := []Log{}
+	if err := WrapDBError(db.Find(&logs, ids)); err != nil {
+		return nil, err
+	}
+	byID := map[uint]Log{}
+	for _, l := range logs {
+		byID[l.ID] = l
+	}
+
+	var merged Log
+	for i, id := range ids {
+		l, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("log %d: %w", id, gorm.ErrRecordNotFound)
+		}
+		if i == 0 {
+			merged = l // Merged with the next one, so never saved as it is.
+			continue
+		}
+		merged = merged.Merge(l)
+	}
+
+	if err := WrapDBError(db.Create(&merged)); err != nil {
+		return nil, err
+	}
+	return &merged, nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeAndSaveInsertsANewLog(t *testing.T) {
+	db, cleanup, err := InMemoryDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	first := Log{Time: time.Now(), Msg: "disk full", Level: 2, LogDetails: []LogDetail{{DetailMsg: "on /var"}}}
+	second := Log{Time: time.Now(), Msg: "retrying", Level: 5}
+	if err := db.Create(&[]*Log{&first, &second}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	merged, err := MergeAndSave(db, []uint{first.ID, second.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged.ID == first.ID || merged.ID == second.ID || merged.Msg != "disk full | retrying" || merged.Level != 5 || len(merged.LogDetails) != 0 {
+		t.Errorf("got %+v; want a new log with msg %q and level 5", merged, "disk full | retrying")
+	}
+
+	if _, err := MergeAndSave(db, []uint{first.ID}); err == nil {
+		t.Error("merging a single log succeeded; want an error")
+	}
+}
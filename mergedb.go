@@ -0,0 +1,57 @@
+package main
+
+import (
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const mergeBatchSize = 500
+
+// MergeDB copies every Log and then every LogDetail of the SQLite database at
+// srcDSN into dst, keeping their IDs. Rows that conflict with existing ones
+// are skipped. It returns how many rows were actually inserted, counted
+// before and after, since RowsAffected also counts the skipped rows when
+// GORM adds RETURNING.
+func MergeDB(dst *gorm.DB, srcDSN string) (int64, error) {
+	src, err := gorm.Open(sqlite.Open(srcDSN), &gorm.Config{Logger: dst.Logger})
+	if err != nil {
+		return 0, err
+	}
+	if sqlDB, err := src.DB(); err == nil {
+		defer sqlDB.Close()
+	}
+
+	before, err := countRows(dst)
+	if err != nil {
+		return 0, err
+	}
+	skipConflicts := dst.Clauses(clause.OnConflict{DoNothing: true}).Session(&gorm.Session{})
+
+	logs := []Log{}
+	err = WrapDBError(src.FindInBatches(&logs, mergeBatchSize, func(tx *gorm.DB, _ int) error {
+		return WrapDBError(skipConflicts.Create(&logs))
+	}))
+	if err == nil {
+		details := []LogDetail{}
+		err = WrapDBError(src.FindInBatches(&details, mergeBatchSize, func(tx *gorm.DB, _ int) error {
+			return WrapDBError(skipConflicts.Create(&details))
+		}))
+	}
+
+	after, countErr := countRows(dst)
+	if err == nil {
+		err = countErr
+	}
+	return after - before, err
+}
+
+// countRows counts logs plus log_details.
+func countRows(db *gorm.DB) (int64, error) {
+	logs, details := int64(0), int64(0)
+	if err := WrapDBError(db.Model(&Log{}).Count(&logs)); err != nil {
+		return 0, err
+	}
+	err := WrapDBError(db.Model(&LogDetail{}).Count(&details))
+	return logs + details, err
+}
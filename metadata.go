@@ -0,0 +1,38 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// LogMetadata is stored as JSON text. It is a Scanner rather than a
+// serializer:json map because GORM skips serializers when scanning into a map,
+// as First(&map[string]interface{}{}) does.
+type LogMetadata map[string]interface{}
+
+func (LogMetadata) GormDataType() string {
+	return "text"
+}
+
+func (m LogMetadata) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	b, err := json.Marshal(m)
+	return string(b), err
+}
+
+func (m *LogMetadata) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), m)
+	case []byte:
+		return json.Unmarshal(v, m)
+	default:
+		return fmt.Errorf("LogMetadata: cannot scan %T", value)
+	}
+}
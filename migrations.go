@@ -0,0 +1,78 @@
+package main
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+//go:embed migrations/*.sql
+var embeddedMigrations embed.FS
+
+var ErrMigrationChanged = errors.New("migration changed after it was applied")
+
+// SchemaMigration records a migration file RunMigrations has applied.
+type SchemaMigration struct {
+	Filename  string `gorm:"primaryKey"`
+	Checksum  string // hex sha256 of the file
+	AppliedAt time.Time
+}
+
+// RunMigrations applies the *.sql files in dir of fsys in lexicographic order,
+// each in its own transaction with its schema_migrations row. Files already
+// applied are skipped, so it can run on every start, but one whose content
+// changed since is ErrMigrationChanged and stops the run: write a new file
+// instead of editing an applied one.
+func RunMigrations(db *gorm.DB, fsys embed.FS, dir string) error {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return err
+	}
+	entries, err := fsys.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	applied := []SchemaMigration{}
+	if err := WrapDBError(db.Find(&applied)); err != nil {
+		return err
+	}
+	checksums := map[string]string{}
+	for _, m := range applied {
+		checksums[m.Filename] = m.Checksum
+	}
+
+	for _, entry := range entries { // ReadDir sorts by name.
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		content, err := fsys.ReadFile(path.Join(dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		sum := sha256.Sum256(content)
+		checksum := hex.EncodeToString(sum[:])
+		if old, ok := checksums[entry.Name()]; ok {
+			if old != checksum {
+				return fmt.Errorf("%s: %w", entry.Name(), ErrMigrationChanged)
+			}
+			continue
+		}
+
+		err = db.Transaction(func(tx *gorm.DB) error {
+			if err := WrapDBError(tx.Exec(string(content))); err != nil {
+				return err
+			}
+			return WrapDBError(tx.Create(&SchemaMigration{Filename: entry.Name(), Checksum: checksum, AppliedAt: time.Now()}))
+		})
+		if err != nil {
+			return fmt.Errorf("%s: %w", entry.Name(), err)
+		}
+	}
+	return nil
+}
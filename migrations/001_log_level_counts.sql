@@ -0,0 +1,6 @@
+-- Live logs per level, for dashboards that shouldn't need to know about soft delete.
+CREATE VIEW log_level_counts AS
+SELECT level, count(*) AS total
+FROM logs
+WHERE deleted_at IS NULL
+GROUP BY level;
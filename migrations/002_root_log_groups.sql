@@ -0,0 +1,3 @@
+-- The top level of the LogGroup tree.
+CREATE VIEW root_log_groups AS
+SELECT * FROM log_groups WHERE parent_id IS NULL;
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WriteThroughMirror writes every log to Primary and then Mirror, and reads
+// only from Primary. Mirror failures are logged, not returned; SyncMirror
+// catches the mirror up afterwards.
+type WriteThroughMirror struct {
+	Primary *gorm.DB
+	Mirror  *gorm.DB
+}
+
+func (m *WriteThroughMirror) Create(log *Log) error {
+	if err := WrapDBError(m.Primary.Create(log)); err != nil {
+		return err
+	}
+	if err := WrapDBError(m.Mirror.Create(log)); err != nil {
+		m.Mirror.Logger.Error(m.Mirror.Statement.Context, "mirror: %v", err)
+	}
+	return nil
+}
+
+func (m *WriteThroughMirror) Find(dest *[]Log, cond ...interface{}) error {
+	return WrapDBError(m.Primary.Find(dest, cond...))
+}
+
+// BulkCreateOrIgnore inserts logs in batches, skipping any that conflict
+// with existing rows.
+func BulkCreateOrIgnore(db *gorm.DB, logs []Log, batchSize int) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return WrapDBError(db.
+		Clauses(clause.OnConflict{DoNothing: true}).
+		CreateInBatches(&logs, batchSize))
+}
+
+// SyncMirror copies the logs missing from the mirror and returns how many
+// rows the mirror gained.
+func SyncMirror(m *WriteThroughMirror) (int64, error) {
+	primaryIDs, mirrorIDs := []uint{}, []uint{}
+	if err := WrapDBError(m.Primary.Model(&Log{}).Pluck("id", &primaryIDs)); err != nil {
+		return 0, err
+	}
+	if err := WrapDBError(m.Mirror.Model(&Log{}).Pluck("id", &mirrorIDs)); err != nil {
+		return 0, err
+	}
+	mirrored := make(map[uint]bool, len(mirrorIDs))
+	for _, id := range mirrorIDs {
+		mirrored[id] = true
+	}
+	missing := []uint{}
+	for _, id := range primaryIDs {
+		if !mirrored[id] {
+			missing = append(missing, id)
+		}
+	}
+
+	for _, ids := range chunkIDs(missing, 500) {
+		logs := []Log{}
+		if err := WrapDBError(m.Primary.Find(&logs, ids)); err != nil {
+			return 0, err
+		}
+		if err := BulkCreateOrIgnore(m.Mirror, logs, 500); err != nil {
+			return 0, err
+		}
+	}
+
+	after := int64(0)
+	err := WrapDBError(m.Mirror.Model(&Log{}).Count(&after))
+	return after - int64(len(mirrorIDs)), err
+}
+
+func chunkIDs(ids []uint, n int) [][]uint {
+	chunks := [][]uint{}
+	for start := 0; start < len(ids); start += n {
+		end := start + n
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunks = append(chunks, ids[start:end])
+	}
+	return chunks
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+var ErrOrphanCountChanged = errors.New("orphan count changed during cleanup")
+
+// CleanOrphanDetails hard-deletes the log details whose log row is gone, which
+// SQLite allows since it doesn't enforce foreign keys by default. Details of a
+// soft-deleted log are not orphans: the log row is still there. The orphans
+// are counted first, in the same transaction, and a delete that removes a
+// different number is rolled back with ErrOrphanCountChanged.
+func CleanOrphanDetails(db *gorm.DB) (int64, error) {
+	deleted := int64(0)
+	err := db.Transaction(func(tx *gorm.DB) error {
+		orphans := func() *gorm.DB {
+			return tx.Unscoped().Where("log_id NOT IN (?)", tx.Unscoped().Model(&Log{}).Select("id"))
+		}
+		expected := int64(0)
+		if err := WrapDBError(orphans().Model(&LogDetail{}).Count(&expected)); err != nil {
+			return err
+		}
+		result := orphans().Delete(&LogDetail{})
+		if err := WrapDBError(result); err != nil {
+			return err
+		}
+		if result.RowsAffected != expected {
+			return fmt.Errorf("%w: counted %d, deleted %d", ErrOrphanCountChanged, expected, result.RowsAffected)
+		}
+		deleted = result.RowsAffected
+		return nil
+	})
+	return deleted, err
+}
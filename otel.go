@@ -0,0 +1,75 @@
+package main
+
+import (
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// OTelPlugin wraps every statement in a client span named gorm.<operation>.
+// The span goes into the statement's context, so queries run by hooks become
+// its children.
+type OTelPlugin struct {
+	tracer trace.Tracer
+}
+
+func NewOTelPlugin(tracer trace.Tracer) *OTelPlugin {
+	return &OTelPlugin{tracer: tracer}
+}
+
+func (p *OTelPlugin) Name() string {
+	return "otel"
+}
+
+func (p *OTelPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().Before("*").Register("otel:start", p.start("create")),
+		cb.Query().Before("*").Register("otel:start", p.start("query")),
+		cb.Update().Before("*").Register("otel:start", p.start("update")),
+		cb.Delete().Before("*").Register("otel:start", p.start("delete")),
+		cb.Row().Before("*").Register("otel:start", p.start("row")),
+		cb.Raw().Before("*").Register("otel:start", p.start("raw")),
+		cb.Create().After("*").Register("otel:end", p.end),
+		cb.Query().After("*").Register("otel:end", p.end),
+		cb.Update().After("*").Register("otel:end", p.end),
+		cb.Delete().After("*").Register("otel:end", p.end),
+		cb.Row().After("*").Register("otel:end", p.end),
+		cb.Raw().After("*").Register("otel:end", p.end),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *OTelPlugin) start(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		ctx, span := p.tracer.Start(tx.Statement.Context, "gorm."+operation,
+			trace.WithSpanKind(trace.SpanKindClient))
+		tx.Statement.Context = ctx
+		tx.InstanceSet("otel:span", span)
+	}
+}
+
+func (p *OTelPlugin) end(tx *gorm.DB) {
+	v, ok := tx.InstanceGet("otel:span")
+	if !ok {
+		return
+	}
+	span := v.(trace.Span)
+	defer span.End()
+
+	span.SetAttributes(
+		attribute.String("db.system", tx.Dialector.Name()),
+		attribute.String("db.statement", tx.Statement.SQL.String()),
+		attribute.String("db.sql.table", tx.Statement.Table),
+		attribute.Int64("db.rows_affected", tx.Statement.RowsAffected),
+	)
+	if tx.Error != nil {
+		span.RecordError(tx.Error)
+		span.SetStatus(codes.Error, tx.Error.Error())
+	}
+}
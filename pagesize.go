@@ -0,0 +1,32 @@
+package main
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// MaxPageSizePlugin caps every query at MaxLimit rows. Note that this also
+// applies to the queries Preload issues.
+type MaxPageSizePlugin struct {
+	MaxLimit int
+}
+
+func (p MaxPageSizePlugin) Name() string {
+	return "max_page_size"
+}
+
+func (p MaxPageSizePlugin) Initialize(db *gorm.DB) error {
+	return db.Callback().Query().Before("gorm:query").Register("max_page_size:limit", p.limit)
+}
+
+func (p MaxPageSizePlugin) limit(tx *gorm.DB) {
+	if c, ok := tx.Statement.Clauses["LIMIT"]; ok {
+		if limit, ok := c.Expression.(clause.Limit); ok && limit.Limit != nil {
+			if *limit.Limit <= p.MaxLimit {
+				return
+			}
+			tx.Logger.Warn(tx.Statement.Context, "limit %d overridden by MaxLimit %d", *limit.Limit, p.MaxLimit)
+		}
+	}
+	tx.Limit(p.MaxLimit)
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var ErrInvalidPage = errors.New("page and page size must be at least 1")
+
+// Paginate loads page (1-based) of db's query into dest, which may be a slice
+// of any model, and returns how many rows the whole query matches.
+func Paginate(db *gorm.DB, page, pageSize int, dest interface{}) (total int64, err error) {
+	if page < 1 || pageSize < 1 {
+		return 0, ErrInvalidPage
+	}
+	base := db.Session(&gorm.Session{})
+	if base.Statement.Model == nil {
+		base = base.Model(dest)
+	}
+	if err := WrapDBError(base.Count(&total)); err != nil {
+		return 0, err
+	}
+	return total, WrapDBError(base.Limit(pageSize).Offset((page - 1) * pageSize).Find(dest))
+}
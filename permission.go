@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrForbidden = errors.New("forbidden")
+
+type roleKey struct{}
+
+// WithRole returns a context whose statements, through db.WithContext(ctx),
+// are checked by PermissionPlugin as role.
+func WithRole(ctx context.Context, role string) context.Context {
+	return context.WithValue(ctx, roleKey{}, role)
+}
+
+// PolicyFn decides about one statement. It returns stmt, after adding any
+// conditions the role is limited to, or nil to refuse it with ErrForbidden.
+// role is "" if the context has none. PermissionOperation tells queries,
+// updates and deletes apart.
+type PolicyFn func(role string, stmt *gorm.Statement) *gorm.Statement
+
+// PermissionPlugin runs Policy before every query, update and delete. Creates
+// and Raw/Exec SQL are not checked. Updates and deletes are checked before
+// their Before hooks run, so a refused statement has no side effects.
+type PermissionPlugin struct {
+	Policy PolicyFn
+}
+
+func (p *PermissionPlugin) Name() string {
+	return "permission"
+}
+
+func (p *PermissionPlugin) Initialize(db *gorm.DB) error {
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Query().Before("gorm:query").Register("permission:check", p.check("query")),
+		cb.Update().Before("gorm:before_update").Register("permission:check", p.check("update")),
+		cb.Delete().Before("gorm:before_delete").Register("permission:check", p.check("delete")),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *PermissionPlugin) check(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		if tx.Error != nil {
+			return
+		}
+		role, _ := tx.Statement.Context.Value(roleKey{}).(string)
+		tx.Statement.Settings.Store("permission:operation", operation)
+		if p.Policy(role, tx.Statement) == nil {
+			tx.AddError(fmt.Errorf("%s %s as %q: %w", operation, tx.Statement.Table, role, ErrForbidden))
+		}
+	}
+}
+
+// PermissionOperation returns "query", "update" or "delete" for a statement
+// being checked by PermissionPlugin.
+func PermissionOperation(stmt *gorm.Statement) string {
+	operation, _ := stmt.Settings.Load("permission:operation")
+	s, _ := operation.(string)
+	return s
+}
+
+// LogOwnerPolicy lets admins do anything and viewers read only the logs they
+// created, as set by WithAuditUser. Other tables are left alone; any other
+// role is refused.
+func LogOwnerPolicy(role string, stmt *gorm.Statement) *gorm.Statement {
+	if stmt.Table != "logs" {
+		return stmt
+	}
+	switch role {
+	case "admin":
+		return stmt
+	case "viewer":
+		userID, ok := auditUser(stmt.DB)
+		if !ok || PermissionOperation(stmt) != "query" {
+			return nil
+		}
+		stmt.AddClause(clause.Where{Exprs: []clause.Expression{
+			clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "created_by"}, Value: userID},
+		}})
+		return stmt
+	default:
+		return nil
+	}
+}
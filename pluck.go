@@ -0,0 +1,27 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// PluckMessages returns the distinct messages, sorted, of the logs at any of
+// levels, or of all logs when none are given. It wraps gorm.ErrRecordNotFound
+// when there are none. Pluck fetches the column alone, so AfterFind does not run.
+func PluckMessages(db *gorm.DB, levels ...int8) ([]string, error) {
+	tx := db.Model(&Log{})
+	if len(levels) > 0 {
+		tx = tx.Where("level IN ?", levels)
+	}
+	msgs := []string{}
+	if err := WrapDBError(tx.Distinct().Pluck("msg", &msgs)); err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 {
+		return nil, fmt.Errorf("no log messages: %w", gorm.ErrRecordNotFound)
+	}
+	sort.Strings(msgs)
+	return msgs, nil
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PoolOptions configures db's *sql.DB. A zero field gets its default:
+// MaxOpenConns 25, MaxIdleConns 5, ConnMaxLifetime 30m and ConnMaxIdleTime 5m.
+type PoolOptions struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+func (o PoolOptions) withDefaults() PoolOptions {
+	if o.MaxOpenConns == 0 {
+		o.MaxOpenConns = 25
+	}
+	if o.MaxIdleConns == 0 {
+		o.MaxIdleConns = 5
+	}
+	if o.ConnMaxLifetime == 0 {
+		o.ConnMaxLifetime = 30 * time.Minute
+	}
+	if o.ConnMaxIdleTime == 0 {
+		o.ConnMaxIdleTime = 5 * time.Minute
+	}
+	return o
+}
+
+// Validate checks the options as ConfigurePool will apply them, defaults included.
+func (o PoolOptions) Validate() error {
+	o = o.withDefaults()
+	switch {
+	case o.MaxOpenConns < 0 || o.MaxIdleConns < 0 || o.ConnMaxLifetime < 0 || o.ConnMaxIdleTime < 0:
+		return errors.New("pool options must not be negative")
+	case o.MaxIdleConns > o.MaxOpenConns:
+		return errors.New("pool MaxIdleConns must not exceed MaxOpenConns")
+	}
+	return nil
+}
+
+func ConfigurePool(db *gorm.DB, opts PoolOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	opts = opts.withDefaults()
+	sqlDB.SetMaxOpenConns(opts.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(opts.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(opts.ConnMaxLifetime)
+	sqlDB.SetConnMaxIdleTime(opts.ConnMaxIdleTime)
+	return nil
+}
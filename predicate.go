@@ -0,0 +1,87 @@
+package main
+
+import (
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Predicate is a WHERE condition built from GORM clauses instead of SQL strings.
+type Predicate interface {
+	Expression() clause.Expression
+}
+
+type EqPredicate struct {
+	Column string
+	Value  interface{}
+}
+
+type LikePredicate struct {
+	Column  string
+	Pattern string
+}
+
+// InPredicate takes any slice as Values.
+type InPredicate struct {
+	Column string
+	Values interface{}
+}
+
+type AndPredicate struct {
+	Children []Predicate
+}
+
+type OrPredicate struct {
+	Children []Predicate
+}
+
+type NotPredicate struct {
+	Children []Predicate
+}
+
+func (p EqPredicate) Expression() clause.Expression {
+	return clause.Eq{Column: clause.Column{Name: p.Column}, Value: p.Value}
+}
+
+func (p LikePredicate) Expression() clause.Expression {
+	return clause.Like{Column: clause.Column{Name: p.Column}, Value: p.Pattern}
+}
+
+func (p InPredicate) Expression() clause.Expression {
+	values := []interface{}{}
+	v := reflect.ValueOf(p.Values)
+	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, v.Index(i).Interface())
+		}
+	} else {
+		values = append(values, p.Values)
+	}
+	return clause.IN{Column: clause.Column{Name: p.Column}, Values: values}
+}
+
+func (p AndPredicate) Expression() clause.Expression {
+	return clause.And(expressions(p.Children)...)
+}
+
+func (p OrPredicate) Expression() clause.Expression {
+	return clause.Or(expressions(p.Children)...)
+}
+
+func (p NotPredicate) Expression() clause.Expression {
+	return clause.Not(expressions(p.Children)...)
+}
+
+func expressions(predicates []Predicate) []clause.Expression {
+	exprs := make([]clause.Expression, 0, len(predicates))
+	for _, p := range predicates {
+		exprs = append(exprs, p.Expression())
+	}
+	return exprs
+}
+
+// ApplyPredicate adds the predicate tree to db as a single WHERE condition.
+func ApplyPredicate(db *gorm.DB, p Predicate) *gorm.DB {
+	return db.Where(p.Expression())
+}
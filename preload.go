@@ -0,0 +1,43 @@
+package main
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+var ErrZeroLogID = errors.New("log has no ID")
+
+// PreloadLogDetails fills LogDetails of every log with one query, like
+// Preload("LogDetails") does for logs loaded by Find. Details already in the
+// slices are replaced. A log without an ID is an error and nothing is loaded.
+func PreloadLogDetails(db *gorm.DB, logs []Log) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	byID := map[uint][]int{}
+	ids := []uint{}
+	for i, log := range logs {
+		if log.ID == 0 {
+			return ErrZeroLogID
+		}
+		if _, ok := byID[log.ID]; !ok {
+			ids = append(ids, log.ID)
+		}
+		byID[log.ID] = append(byID[log.ID], i)
+	}
+
+	details := []LogDetail{}
+	if err := WrapDBError(db.Where("log_id IN ?", ids).Order("id").Find(&details)); err != nil {
+		return err
+	}
+	for i := range logs {
+		logs[i].LogDetails = []LogDetail{}
+	}
+	for _, detail := range details {
+		for _, i := range byID[detail.LogID] {
+			logs[i].LogDetails = append(logs[i].LogDetails, detail)
+		}
+	}
+	return nil
+}
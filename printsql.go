@@ -0,0 +1,16 @@
+package main
+
+import "gorm.io/gorm"
+
+// ToSQL returns the SQL fn would run on db, values inlined, without running it:
+//
+//	ToSQL(db, func(tx *gorm.DB) *gorm.DB { return tx.Where("level > ?", 3).Find(&[]Log{}) })
+func ToSQL(db *gorm.DB, fn func(*gorm.DB) *gorm.DB) string {
+	return db.ToSQL(fn)
+}
+
+// PrintSQL logs label and the SQL of fn through db's logger. GORM's logger has
+// no debug level, so it goes out at Info and is hidden below that.
+func PrintSQL(label string, db *gorm.DB, fn func(*gorm.DB) *gorm.DB) {
+	db.Logger.Info(db.Statement.Context, "%s: %s", label, ToSQL(db, fn))
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var ErrQuotaExceeded = errors.New("logs quota exceeded")
+
+// QuotaPlugin rejects inserts into logs once it holds MaxRows rows. The row
+// count is cached: inserts bump it, and it is recounted every 5 seconds.
+type QuotaPlugin struct {
+	MaxRows int64
+
+	count int64
+	stop  chan struct{}
+}
+
+func (p *QuotaPlugin) Name() string {
+	return "quota"
+}
+
+func (p *QuotaPlugin) Initialize(db *gorm.DB) error {
+	p.stop = make(chan struct{})
+	p.refresh(db)
+	go func() {
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+				p.refresh(db)
+			}
+		}
+	}()
+
+	err := db.Callback().Create().Before("gorm:create").Register("quota:check", func(tx *gorm.DB) {
+		if tx.Statement.Table == "logs" && atomic.LoadInt64(&p.count) >= p.MaxRows {
+			tx.AddError(ErrQuotaExceeded)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	return db.Callback().Create().After("gorm:create").Register("quota:count", func(tx *gorm.DB) {
+		if tx.Statement.Table == "logs" && tx.Error == nil {
+			atomic.AddInt64(&p.count, tx.RowsAffected)
+		}
+	})
+}
+
+// SELECT count(*) FROM `logs`
+func (p *QuotaPlugin) refresh(db *gorm.DB) {
+	count := int64(0)
+	if err := db.Table("logs").Count(&count).Error; err == nil {
+		atomic.StoreInt64(&p.count, count)
+	}
+}
+
+// Stop ends the background recount.
+func (p *QuotaPlugin) Stop() {
+	close(p.stop)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// QueryRecorder captures the SQL a session runs so that tests can assert on it.
+type QueryRecorder struct {
+	mu      sync.Mutex
+	queries []string
+}
+
+// recordingLogger records every traced statement and then hands it on.
+type recordingLogger struct {
+	logger.Interface
+	recorder *QueryRecorder
+}
+
+func (l recordingLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return recordingLogger{Interface: l.Interface.LogMode(level), recorder: l.recorder}
+}
+
+func (l recordingLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, rows := fc()
+	l.recorder.mu.Lock()
+	l.recorder.queries = append(l.recorder.queries, sql)
+	l.recorder.mu.Unlock()
+	l.Interface.Trace(ctx, begin, func() (string, int64) { return sql, rows }, err)
+}
+
+// Start returns a session of db whose statements are recorded.
+func (r *QueryRecorder) Start(db *gorm.DB) *gorm.DB {
+	return db.Session(&gorm.Session{Logger: recordingLogger{Interface: db.Logger, recorder: r}})
+}
+
+func (r *QueryRecorder) Queries() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.queries...)
+}
+
+func (r *QueryRecorder) Reset() {
+	r.mu.Lock()
+	r.queries = nil
+	r.mu.Unlock()
+}
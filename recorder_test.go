@@ -0,0 +1,46 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func (r *QueryRecorder) AssertContains(t testing.TB, partial string) {
+	t.Helper()
+	for _, q := range r.Queries() {
+		if strings.Contains(q, partial) {
+			return
+		}
+	}
+	t.Errorf("no recorded query contains %q", partial)
+}
+
+func (r *QueryRecorder) AssertQueryCount(t testing.TB, n int) {
+	t.Helper()
+	if got := len(r.Queries()); got != n {
+		t.Errorf("recorded %d queries, want %d", got, n)
+	}
+}
+
+// Three logs in batches of two, as in the insertInBatches demo.
+func TestInsertInBatchesIssuesTwoInserts(t *testing.T) {
+	db, cleanup, err := InMemoryDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+
+	recorder := QueryRecorder{}
+	logs := []Log{{Msg: "a"}, {Msg: "b"}, {Msg: "c"}}
+	if err := recorder.Start(db).CreateInBatches(&logs, 2).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	recorder.AssertQueryCount(t, 2)
+	recorder.AssertContains(t, "INSERT INTO `logs`")
+	for _, q := range recorder.Queries() {
+		if !strings.HasPrefix(q, "INSERT INTO `logs`") {
+			t.Errorf("unexpected query %q", q)
+		}
+	}
+}
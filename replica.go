@@ -0,0 +1,51 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ReplicaLagSimulator makes reads on a secondary DB arrive Lag late.
+type ReplicaLagSimulator struct {
+	DB  *gorm.DB
+	Lag time.Duration
+}
+
+func (r *ReplicaLagSimulator) WithContext(ctx context.Context) *ReplicaLagSimulator {
+	return &ReplicaLagSimulator{DB: r.DB.WithContext(ctx), Lag: r.Lag}
+}
+
+// Find waits for Lag, or until the context is done, then reads from the replica.
+func (r *ReplicaLagSimulator) Find(dest interface{}, conds ...interface{}) *gorm.DB {
+	select {
+	case <-time.After(r.Lag):
+		return r.DB.Find(dest, conds...)
+	case <-r.DB.Statement.Context.Done():
+		tx := r.DB.Session(&gorm.Session{})
+		tx.AddError(r.DB.Statement.Context.Err())
+		return tx
+	}
+}
+
+// ReadWriteDB writes to Primary and reads from Replica, unless the replica
+// takes longer than MaxLag to answer.
+type ReadWriteDB struct {
+	Primary *gorm.DB
+	Replica *ReplicaLagSimulator
+	MaxLag  time.Duration
+}
+
+// Find returns "replica" or "primary" depending on which DB answered.
+func (rw *ReadWriteDB) Find(dest interface{}, conds ...interface{}) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), rw.MaxLag)
+	defer cancel()
+
+	result := rw.Replica.WithContext(ctx).Find(dest, conds...)
+	if errors.Is(result.Error, context.DeadlineExceeded) {
+		return "primary", WrapDBError(rw.Primary.Find(dest, conds...))
+	}
+	return "replica", WrapDBError(result)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"gorm.io/gorm"
+)
+
+// LogRepositoryInterface is what callers of LogRepository should depend on,
+// so that tests can pass a fake instead.
+type LogRepositoryInterface interface {
+	Create(log *Log) error
+	FindByID(id uint) (*Log, error)
+	FindByLevel(level int8) ([]Log, error)
+	Update(log *Log) error
+	Delete(id uint) error
+	CountByLevel(level int8) (int64, error)
+	Stats(ctx context.Context) (LogStats, error)
+	Search(ctx context.Context, query string) ([]Log, error)
+}
+
+var _ LogRepositoryInterface = (*LogRepository)(nil)
+
+// LogRepository wraps the common operations on logs. Every error comes back
+// as a *DBError.
+type LogRepository struct {
+	db *gorm.DB
+}
+
+func NewLogRepository(db *gorm.DB) *LogRepository {
+	return &LogRepository{db: db}
+}
+
+func (r *LogRepository) Create(log *Log) error {
+	return WrapDBError(r.db.Create(log))
+}
+
+// FindByID wraps gorm.ErrRecordNotFound if there is no such log.
+func (r *LogRepository) FindByID(id uint) (*Log, error) {
+	log := Log{}
+	if err := WrapDBError(r.db.First(&log, id)); err != nil {
+		return nil, err
+	}
+	return &log, nil
+}
+
+func (r *LogRepository) FindByLevel(level int8) ([]Log, error) {
+	logs := []Log{}
+	if err := WrapDBError(r.db.Scopes(WithExactLevel(level)).Order("id").Find(&logs)); err != nil {
+		return nil, err
+	}
+	return logs, nil
+}
+
+// Update saves every field of log, which must have been loaded first.
+func (r *LogRepository) Update(log *Log) error {
+	return WrapDBError(r.db.Save(log))
+}
+
+// Delete soft-deletes the log. Deleting a missing log is not an error.
+func (r *LogRepository) Delete(id uint) error {
+	return WrapDBError(r.db.Delete(&Log{}, id))
+}
+
+func (r *LogRepository) CountByLevel(level int8) (int64, error) {
+	count := int64(0)
+	err := WrapDBError(r.db.Model(&Log{}).Scopes(WithExactLevel(level)).Count(&count))
+	return count, err
+}
+
+// LogStats summarises the live logs. With no logs everything is zero.
+type LogStats struct {
+	TotalCount   int64
+	MinLevel     int8
+	MaxLevel     int8
+	AvgLevel     float64
+	EarliestTime time.Time
+	LatestTime   time.Time
+}
+
+// logStatsQuery selects the columns of LogStats in one row.
+func logStatsQuery(db *gorm.DB) *gorm.DB {
+	return db.Model(&Log{}).Select("count(*), coalesce(min(level), 0), coalesce(max(level), 0), " +
+		"coalesce(avg(level), 0), min(time), max(time)")
+}
+
+// Stats aggregates in SQL; no Log is loaded.
+func (r *LogRepository) Stats(ctx context.Context) (LogStats, error) {
+	stats := LogStats{}
+	earliest, latest := aggregateTime{}, aggregateTime{}
+	result := logStatsQuery(r.db.WithContext(ctx))
+	err := result.Row().Scan(&stats.TotalCount, &stats.MinLevel, &stats.MaxLevel, &stats.AvgLevel, &earliest, &latest)
+	if err != nil {
+		result.AddError(err)
+		return LogStats{}, WrapDBError(result)
+	}
+	stats.EarliestTime, stats.LatestTime = earliest.Time, latest.Time
+	return stats, nil
+}
+
+// aggregateTime scans min(time) and the like. SQLite only knows a column
+// holds times from its declared type, which an aggregate doesn't have, so
+// they come back as text in one of the formats the driver writes.
+type aggregateTime struct {
+	sql.NullTime
+}
+
+func (t *aggregateTime) Scan(value interface{}) error {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return t.NullTime.Scan(value)
+	}
+	for _, layout := range sqlite3.SQLiteTimestampFormats {
+		if parsed, err := time.Parse(layout, s); err == nil {
+			t.Time, t.Valid = parsed, true
+			return nil
+		}
+	}
+	return fmt.Errorf("aggregateTime: cannot parse %q", s)
+}
+
+// Search finds logs whose Msg matches an FTS5 query, such as "disk AND full"
+// or "conn*", best match first (by BM25, FTS5's rank). SetupFTS must have
+// been run on the database, which needs -tags sqlite_fts5.
+func (r *LogRepository) Search(ctx context.Context, query string) ([]Log, error) {
+	logs := []Log{}
+	err := WrapDBError(r.db.WithContext(ctx).
+		Select("logs.*").
+		Joins("JOIN logs_fts ON logs_fts.rowid = logs.id").
+		Where("logs_fts MATCH ?", query).
+		Order("logs_fts.rank").
+		Find(&logs))
+	return logs, err
+}
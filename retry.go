@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type retryConfig struct {
+	backoff time.Duration
+	jitter  time.Duration
+}
+
+type RetryOption func(*retryConfig)
+
+// WithBackoff sets the sleep before the first retry (default 50ms). It doubles
+// after every further failure.
+func WithBackoff(d time.Duration) RetryOption {
+	return func(c *retryConfig) {
+		c.backoff = d
+	}
+}
+
+// WithJitter adds a random extra of up to d to every sleep, so that writers
+// that collided once don't retry in lockstep.
+func WithJitter(d time.Duration) RetryOption {
+	return func(c *retryConfig) {
+		c.jitter = d
+	}
+}
+
+func (c retryConfig) sleep(backoff time.Duration) {
+	if c.jitter > 0 {
+		backoff += time.Duration(rand.Int63n(int64(c.jitter)))
+	}
+	time.Sleep(backoff)
+}
+
+// RunWithRetry runs fn in a transaction, and runs it again in a new one if
+// the database gave up on it because of lock contention, at most maxAttempts
+// times in all. Any other error is returned straight away.
+func RunWithRetry(db *gorm.DB, maxAttempts int, fn func(*gorm.DB) error, opts ...RetryOption) error {
+	if maxAttempts <= 0 {
+		return errors.New("RunWithRetry: maxAttempts must be positive")
+	}
+	config := retryConfig{backoff: 50 * time.Millisecond}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	var err error
+	backoff := config.backoff
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if err = db.Transaction(fn); err == nil || !isRetryable(err) {
+			return err
+		}
+		if attempt < maxAttempts {
+			config.sleep(backoff)
+			backoff *= 2
+		}
+	}
+	return err
+}
+
+// CreateWithRetry is db.Create(value), retried up to maxRetries more times
+// while SQLite reports "database is locked". The first retry waits backoff,
+// each later one twice as long as the one before. Any other error is
+// returned straight away.
+func CreateWithRetry(db *gorm.DB, value interface{}, maxRetries int, backoff time.Duration, opts ...RetryOption) error {
+	if maxRetries < 0 {
+		return errors.New("CreateWithRetry: maxRetries must not be negative")
+	}
+	config := retryConfig{backoff: backoff}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	var err error
+	for retry := 0; ; retry++ {
+		err = WrapDBError(db.Create(value))
+		if err == nil || !strings.Contains(err.Error(), "database is locked") || retry == maxRetries {
+			return err
+		}
+		config.sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// isRetryable matches SQLite's SQLITE_BUSY and SQLITE_LOCKED, and Postgres'
+// serialization_failure (40001) and deadlock_detected (40P01).
+func isRetryable(err error) bool {
+	msg := err.Error()
+	for _, s := range []string{
+		"database is locked", "database table is locked", "SQLITE_BUSY",
+		"40001", "could not serialize access",
+		"40P01", "deadlock detected",
+	} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package main
+
+import "gorm.io/gorm"
+
+// RWSplitter sends reads (Find, First, Last, Count, Pluck) to Replica and
+// writes (Create, Save, Update, Updates, Delete) to Primary. Where and Model
+// apply to both, so chains read the same as on a *gorm.DB.
+//
+// Replicas lag behind: a read right after a write may not see it. Read from
+// Primary directly when that matters, or see ReadWriteDB for a lag cutoff.
+type RWSplitter struct {
+	Primary *gorm.DB
+	Replica *gorm.DB
+}
+
+func (rw *RWSplitter) Model(value interface{}) *RWSplitter {
+	return &RWSplitter{Primary: rw.Primary.Model(value), Replica: rw.Replica.Model(value)}
+}
+
+func (rw *RWSplitter) Where(query interface{}, args ...interface{}) *RWSplitter {
+	return &RWSplitter{Primary: rw.Primary.Where(query, args...), Replica: rw.Replica.Where(query, args...)}
+}
+
+func (rw *RWSplitter) Find(dest interface{}, conds ...interface{}) *gorm.DB {
+	return rw.Replica.Find(dest, conds...)
+}
+
+func (rw *RWSplitter) First(dest interface{}, conds ...interface{}) *gorm.DB {
+	return rw.Replica.First(dest, conds...)
+}
+
+func (rw *RWSplitter) Last(dest interface{}, conds ...interface{}) *gorm.DB {
+	return rw.Replica.Last(dest, conds...)
+}
+
+func (rw *RWSplitter) Count(count *int64) *gorm.DB {
+	return rw.Replica.Count(count)
+}
+
+func (rw *RWSplitter) Pluck(column string, dest interface{}) *gorm.DB {
+	return rw.Replica.Pluck(column, dest)
+}
+
+func (rw *RWSplitter) Create(value interface{}) *gorm.DB {
+	return rw.Primary.Create(value)
+}
+
+func (rw *RWSplitter) Save(value interface{}) *gorm.DB {
+	return rw.Primary.Save(value)
+}
+
+func (rw *RWSplitter) Update(column string, value interface{}) *gorm.DB {
+	return rw.Primary.Update(column, value)
+}
+
+func (rw *RWSplitter) Updates(values interface{}) *gorm.DB {
+	return rw.Primary.Updates(values)
+}
+
+func (rw *RWSplitter) Delete(value interface{}, conds ...interface{}) *gorm.DB {
+	return rw.Primary.Delete(value, conds...)
+}
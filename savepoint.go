@@ -0,0 +1,31 @@
+package main
+
+import "gorm.io/gorm"
+
+// NestedInsert creates log and then each batch of its details in one
+// transaction. A batch that fails is rolled back to the savepoint taken just
+// before it, so the log and the other batches still commit. It returns how
+// many batches were dropped.
+func NestedInsert(db *gorm.DB, log *Log, batches [][]LogDetail) (dropped int, err error) {
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(log).Error; err != nil {
+			return err
+		}
+		for _, batch := range batches {
+			for i := range batch {
+				batch[i].LogID = log.ID
+			}
+			if err := tx.SavePoint("sp1").Error; err != nil {
+				return err
+			}
+			if err := tx.Create(&batch).Error; err != nil {
+				if err := tx.RollbackTo("sp1").Error; err != nil {
+					return err
+				}
+				dropped++
+			}
+		}
+		return nil
+	})
+	return dropped, err
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// SchemaDiff returns the DDL that would bring the database in line with
+// models: columns to add, to drop and to change type. Nothing is run, and
+// SQLite itself cannot ALTER COLUMN, so treat the output as a report.
+func SchemaDiff(db *gorm.DB, models ...interface{}) ([]string, error) {
+	ddl := []string{}
+	migrator := db.Migrator()
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+		table := stmt.Quote(stmt.Schema.Table)
+		fields := []*schema.Field{}
+		for _, field := range stmt.Schema.Fields {
+			if field.DBName != "" && !field.IgnoreMigration {
+				fields = append(fields, field)
+			}
+		}
+
+		if !migrator.HasTable(model) {
+			columns := []string{}
+			for _, field := range fields {
+				columns = append(columns, stmt.Quote(field.DBName)+" "+migrator.FullDataTypeOf(field).SQL)
+			}
+			ddl = append(ddl, fmt.Sprintf("CREATE TABLE %s (%s)", table, strings.Join(columns, ", ")))
+			continue
+		}
+
+		columnTypes, err := migrator.ColumnTypes(model)
+		if err != nil {
+			return nil, err
+		}
+		live := map[string]gorm.ColumnType{}
+		for _, columnType := range columnTypes {
+			live[columnType.Name()] = columnType
+		}
+		for _, field := range fields {
+			column := stmt.Quote(field.DBName)
+			columnType, ok := live[field.DBName]
+			delete(live, field.DBName)
+			if !ok {
+				ddl = append(ddl, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, migrator.FullDataTypeOf(field).SQL))
+				continue
+			}
+			want, got := db.Dialector.DataTypeOf(field), columnType.DatabaseTypeName()
+			if !sameColumnType(migrator, want, got) {
+				ddl = append(ddl, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s -- was %s", table, column, want, got))
+			}
+		}
+		for _, columnType := range columnTypes {
+			if _, extra := live[columnType.Name()]; extra {
+				ddl = append(ddl, fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", table, stmt.Quote(columnType.Name())))
+			}
+		}
+	}
+	return ddl, nil
+}
+
+func sameColumnType(migrator gorm.Migrator, want, got string) bool {
+	if strings.EqualFold(want, got) {
+		return true
+	}
+	for _, alias := range migrator.GetTypeAliases(got) {
+		if strings.EqualFold(want, alias) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Scopes for the usual log queries, e.g. db.Scopes(WarningsAndAbove).Find(&logs).
+
+func WithMinLevel(level int8) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("level >= ?", level)
+	}
+}
+
+func WithExactLevel(level int8) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("level = ?", level)
+	}
+}
+
+// ErrorsOnly leaves out FATAL logs too.
+func ErrorsOnly(db *gorm.DB) *gorm.DB {
+	return WithExactLevel(LevelError)(db)
+}
+
+func WarningsAndAbove(db *gorm.DB) *gorm.DB {
+	return WithMinLevel(LevelWarn)(db)
+}
+
+// LoggedBetween includes from and excludes to.
+func LoggedBetween(from, to time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("time >= ? AND time < ?", from, to)
+	}
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newScratchDB opens a private in-memory database, for demos whose plugins or
+// hooks would get in the way of everything else on log.db.
+func newScratchDB(name string, models ...interface{}) *gorm.DB {
+	db, _ := gorm.Open(sqlite.Open("file:"+name+"?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Info),
+	})
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1) // Every connection to :memory: is a new database.
+	db.AutoMigrate(models...)
+	return db
+}
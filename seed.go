@@ -0,0 +1,61 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DataSeeder inserts a fixed set of fixtures: one log per level 1 to 5, each
+// with two details. Rows that already exist are left alone, so it can be run
+// on every start.
+type DataSeeder struct {
+	// DryRun only reports how many rows the fixtures have, without any query.
+	DryRun bool
+}
+
+// Seed returns how many rows it inserted, or would insert on an empty
+// database if s.DryRun is set.
+func (s DataSeeder) Seed(db *gorm.DB) (int, error) {
+	if s.DryRun {
+		return 5 * (1 + 2), nil
+	}
+	created := 0
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for level := int8(1); level <= 5; level++ {
+			log := Log{}
+			result := tx.
+				Where(map[string]interface{}{"msg": fmt.Sprintf("seed level %d", level), "level": level}).
+				Attrs(Log{Time: time.Date(2022, 10, 20, 0, 0, 0, 0, time.UTC)}).
+				FirstOrCreate(&log)
+			if err := WrapDBError(result); err != nil {
+				return err
+			}
+			created += int(result.RowsAffected)
+
+			for i := 1; i <= 2; i++ {
+				detail := LogDetail{}
+				result := tx.
+					Where(LogDetail{LogID: log.ID, DetailMsg: fmt.Sprintf("seed level %d detail %d", level, i)}).
+					FirstOrCreate(&detail)
+				if err := WrapDBError(result); err != nil {
+					return err
+				}
+				created += int(result.RowsAffected)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	db.Logger.Info(db.Statement.Context, "seed: %d rows created", created)
+	return created, nil
+}
+
+// Seed inserts the DataSeeder fixtures.
+func Seed(db *gorm.DB) error {
+	_, err := DataSeeder{}.Seed(db)
+	return err
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const shardedLevels = 10
+
+var ErrFlushTimeout = errors.New("flush timed out")
+
+// LevelShardedWriter inserts logs from one worker goroutine per level (0..9).
+// Create never blocks: when a level's buffer is full the log is dropped and
+// counted in Dropped. Inserts that fail are logged and counted in Failed.
+type LevelShardedWriter struct {
+	dropped int64
+	failed  int64
+
+	db      *gorm.DB
+	shards  [shardedLevels]chan Log
+	pending sync.WaitGroup
+	done    sync.WaitGroup
+}
+
+func NewLevelShardedWriter(db *gorm.DB, bufferSize int) *LevelShardedWriter {
+	w := &LevelShardedWriter{db: db}
+	for level := range w.shards {
+		w.shards[level] = make(chan Log, bufferSize)
+		w.done.Add(1)
+		go w.work(w.shards[level])
+	}
+	return w
+}
+
+func (w *LevelShardedWriter) work(shard chan Log) {
+	defer w.done.Done()
+	for log := range shard {
+		if err := WrapDBError(w.db.Create(&log)); err != nil {
+			atomic.AddInt64(&w.failed, 1)
+			w.db.Logger.Error(w.db.Statement.Context, "LevelShardedWriter: %v", err)
+		}
+		w.pending.Done()
+	}
+}
+
+// Dropped is how many logs Create dropped for a full buffer.
+func (w *LevelShardedWriter) Dropped() int64 {
+	return atomic.LoadInt64(&w.dropped)
+}
+
+// Failed is how many queued logs could not be inserted.
+func (w *LevelShardedWriter) Failed() int64 {
+	return atomic.LoadInt64(&w.failed)
+}
+
+// Create queues log on its level's worker. Levels outside 0..9 are clamped.
+func (w *LevelShardedWriter) Create(log Log) {
+	level := int(log.Level)
+	if level < 0 {
+		level = 0
+	} else if level >= shardedLevels {
+		level = shardedLevels - 1
+	}
+	w.pending.Add(1)
+	select {
+	case w.shards[level] <- log:
+	default:
+		w.pending.Done()
+		atomic.AddInt64(&w.dropped, 1)
+	}
+}
+
+// Flush waits until every queued log has been written.
+func (w *LevelShardedWriter) Flush(timeout time.Duration) error {
+	flushed := make(chan struct{})
+	go func() {
+		w.pending.Wait()
+		close(flushed)
+	}()
+	select {
+	case <-flushed:
+		return nil
+	case <-time.After(timeout):
+		return ErrFlushTimeout
+	}
+}
+
+// Close stops the workers once they have written what is queued.
+func (w *LevelShardedWriter) Close() {
+	for _, shard := range w.shards {
+		close(shard)
+	}
+	w.done.Wait()
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ShardedLog is a log kept in one of several logs_<shard> tables. It has no
+// named indexes, since SQLite index names are per database, not per table.
+type ShardedLog struct {
+	ID    uint
+	Shard int `gorm:"-"`
+	Time  time.Time
+	Msg   string
+	Level int8
+}
+
+// TableName gives the table of l's shard. GORM caches a model's table name
+// the first time it parses the type, so queries must go through ShardRouter
+// (which uses db.Table) to reach any shard but the first one parsed.
+func (l ShardedLog) TableName() string {
+	return shardTable(l.Shard)
+}
+
+func shardTable(shardID int) string {
+	return fmt.Sprintf("logs_%d", shardID)
+}
+
+// ShardRouter returns a session of db on shardID's table.
+func ShardRouter(db *gorm.DB, shardID int) *gorm.DB {
+	return db.Table(shardTable(shardID))
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"sort"
+
+	"gorm.io/gorm"
+)
+
+// bigrams returns the set of adjacent rune pairs in s.
+func bigrams(s string) map[string]struct{} {
+	runes := []rune(s)
+	set := map[string]struct{}{}
+	for i := 0; i+1 < len(runes); i++ {
+		set[string(runes[i:i+2])] = struct{}{}
+	}
+	return set
+}
+
+func jaccard(a, b map[string]struct{}) float64 {
+	if len(a) == 0 && len(b) == 0 {
+		return 1
+	}
+	intersection := 0
+	for k := range a {
+		if _, ok := b[k]; ok {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
+
+// NearDuplicates returns the other logs whose Msg bigrams have a Jaccard
+// similarity above threshold with l.Msg, most similar first. On PostgreSQL
+// only a 10% TABLESAMPLE of the table is compared.
+func (l Log) NearDuplicates(db *gorm.DB, threshold float64) ([]Log, error) {
+	candidates := []Log{}
+	query := db.Model(&Log{})
+	if db.Dialector.Name() == "postgres" {
+		query = db.Table("logs TABLESAMPLE SYSTEM (10)")
+	}
+	if err := WrapDBError(query.Where("id <> ?", l.ID).Find(&candidates)); err != nil {
+		return nil, err
+	}
+
+	target := bigrams(l.Msg)
+	type scored struct {
+		log   Log
+		score float64
+	}
+	matches := []scored{}
+	for _, c := range candidates {
+		if score := jaccard(target, bigrams(c.Msg)); score > threshold {
+			matches = append(matches, scored{c, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].score > matches[j].score })
+
+	logs := make([]Log, 0, len(matches))
+	for _, m := range matches {
+		logs = append(logs, m.log)
+	}
+	return logs, nil
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm/logger"
+)
+
+const DefaultSlowThreshold = 100 * time.Millisecond
+
+// SlowQueryLogger hands every statement on to the wrapped logger and also
+// calls WarnFn for the ones that took longer than SlowThreshold (or
+// DefaultSlowThreshold when it is zero). Set it as gorm.Config.Logger.
+type SlowQueryLogger struct {
+	logger.Interface
+	SlowThreshold time.Duration
+	WarnFn        func(sql string, duration time.Duration)
+}
+
+func (l SlowQueryLogger) LogMode(level logger.LogLevel) logger.Interface {
+	l.Interface = l.Interface.LogMode(level)
+	return l
+}
+
+func (l SlowQueryLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	threshold := l.SlowThreshold
+	if threshold == 0 {
+		threshold = DefaultSlowThreshold
+	}
+	if elapsed := time.Since(begin); elapsed > threshold && l.WarnFn != nil {
+		sql, rows := fc()
+		l.WarnFn(sql, elapsed)
+		fc = func() (string, int64) { return sql, rows }
+	}
+	l.Interface.Trace(ctx, begin, fc, err)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// StatsDPlugin sends <Prefix>.gorm.<operation>:<elapsed_ms>|ms to Addr after
+// every statement.
+type StatsDPlugin struct {
+	Addr   string
+	Prefix string
+
+	conn *net.UDPConn
+}
+
+func (p *StatsDPlugin) Name() string {
+	return "statsd"
+}
+
+func (p *StatsDPlugin) Initialize(db *gorm.DB) error {
+	addr, err := net.ResolveUDPAddr("udp", p.Addr)
+	if err != nil {
+		return err
+	}
+	if p.conn, err = net.DialUDP("udp", nil, addr); err != nil {
+		return err
+	}
+
+	start := func(tx *gorm.DB) {
+		tx.InstanceSet("statsd:start", time.Now())
+	}
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().Before("*").Register("statsd:start", start),
+		cb.Query().Before("*").Register("statsd:start", start),
+		cb.Update().Before("*").Register("statsd:start", start),
+		cb.Delete().Before("*").Register("statsd:start", start),
+		cb.Row().Before("*").Register("statsd:start", start),
+		cb.Raw().Before("*").Register("statsd:start", start),
+		cb.Create().After("*").Register("statsd:send", p.send("create")),
+		cb.Query().After("*").Register("statsd:send", p.send("query")),
+		cb.Update().After("*").Register("statsd:send", p.send("update")),
+		cb.Delete().After("*").Register("statsd:send", p.send("delete")),
+		cb.Row().After("*").Register("statsd:send", p.send("row")),
+		cb.Raw().After("*").Register("statsd:send", p.send("raw")),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *StatsDPlugin) send(operation string) func(*gorm.DB) {
+	return func(tx *gorm.DB) {
+		start, ok := tx.InstanceGet("statsd:start")
+		if !ok {
+			return
+		}
+		elapsed := float64(time.Since(start.(time.Time))) / float64(time.Millisecond)
+		// Metrics are best effort; a lost datagram is not the query's problem.
+		fmt.Fprintf(p.conn, "%s.gorm.%s:%.3f|ms", p.Prefix, operation, elapsed)
+	}
+}
+
+func (p *StatsDPlugin) Close() error {
+	if p.conn == nil {
+		return nil
+	}
+	return p.conn.Close()
+}
@@ -0,0 +1,48 @@
+package main
+
+import "gorm.io/gorm"
+
+type streamConfig struct {
+	batchSize int
+}
+
+type StreamOption func(*streamConfig)
+
+// WithBatchSize sets how many logs StreamLogs loads per query (default 500).
+func WithBatchSize(n int) StreamOption {
+	return func(c *streamConfig) {
+		if n > 0 {
+			c.batchSize = n
+		}
+	}
+}
+
+// StreamLogs calls handler for every log matched by db, loading them in
+// batches so only one batch is in memory at a time. The *Log is reused, so
+// copy it to keep it.
+//
+// The first error stops the stream; logs already handled stay handled. An
+// error from handler is returned unchanged, even a gorm one such as
+// gorm.ErrRecordNotFound from its own query, so errors.Is and == both work. An
+// error from loading a batch is returned as a *DBError.
+func StreamLogs(db *gorm.DB, handler func(*Log) error, opts ...StreamOption) error {
+	config := streamConfig{batchSize: 500}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	var handlerErr error
+	batch := []Log{}
+	result := db.FindInBatches(&batch, config.batchSize, func(tx *gorm.DB, _ int) error {
+		for i := range batch {
+			if handlerErr = handler(&batch[i]); handlerErr != nil {
+				return handlerErr
+			}
+		}
+		return nil
+	})
+	if handlerErr != nil {
+		return handlerErr
+	}
+	return WrapDBError(result)
+}
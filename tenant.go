@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+)
+
+type tenantKey struct{}
+
+func TenantScope(tenantID uint) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("tenant_id = ?", tenantID)
+	}
+}
+
+// WithTenant returns a session whose every query, update and delete is
+// limited to tenantID's rows, and whose creates set TenantID when it is 0.
+func WithTenant(db *gorm.DB, tenantID uint) *gorm.DB {
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return db.
+		WithContext(context.WithValue(ctx, tenantKey{}, tenantID)).
+		Scopes(TenantScope(tenantID)).
+		Session(&gorm.Session{})
+}
+
+func (u *Log) setTenant(tx *gorm.DB) {
+	if tenantID, ok := tx.Statement.Context.Value(tenantKey{}).(uint); ok && u.TenantID == 0 {
+		u.TenantID = tenantID
+	}
+}
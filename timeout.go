@@ -0,0 +1,21 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WithQueryTimeout returns a session whose queries fail with
+// context.DeadlineExceeded once d has passed, and the func that releases its
+// context. The clock starts now, not per query, so take a new session for
+// each operation and call cancel once it's done, as with context.WithTimeout.
+func WithQueryTimeout(db *gorm.DB, d time.Duration) (*gorm.DB, context.CancelFunc) {
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, d)
+	return db.WithContext(ctx), cancel
+}
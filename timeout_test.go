@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// PRAGMA integrity_check walks every page, so a table of random blobs makes
+// it slow enough to outlast the deadline.
+func TestWithQueryTimeoutInterruptsSlowPragma(t *testing.T) {
+	db, cleanup, err := InMemoryDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	for _, sql := range []string{
+		"CREATE TABLE filler (blob BLOB)",
+		"CREATE INDEX idx_filler_blob ON filler (blob)",
+		"INSERT INTO filler WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 50000) SELECT randomblob(100) FROM n",
+	} {
+		if err := db.Exec(sql).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	timed, cancel := WithQueryTimeout(db, 5*time.Millisecond)
+	defer cancel()
+	result := ""
+	err = timed.Raw("PRAGMA integrity_check").Row().Scan(&result)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("got %v (result %q), want context.DeadlineExceeded", err, result)
+	}
+
+	if err := db.Raw("PRAGMA integrity_check").Row().Scan(&result); err != nil || result != "ok" {
+		t.Fatalf("without a timeout got %q, %v; want ok", result, err)
+	}
+}
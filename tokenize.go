@@ -0,0 +1,95 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+)
+
+// StopWords are dropped by Tokenize. Replace them with LoadStopWords.
+var StopWords = map[string]struct{}{
+	"a": {}, "an": {}, "and": {}, "the": {}, "of": {}, "to": {}, "in": {}, "is": {},
+}
+
+// LoadStopWords replaces StopWords with the words in path, one per line.
+func LoadStopWords(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	words := map[string]struct{}{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if word := strings.ToLower(strings.TrimSpace(scanner.Text())); word != "" {
+			words[word] = struct{}{}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	StopWords = words
+	return nil
+}
+
+// Tokenize lowercases Msg, strips punctuation and drops StopWords.
+func (l Log) Tokenize() []string {
+	stripped := strings.Map(func(r rune) rune {
+		if unicode.IsPunct(r) {
+			return -1
+		}
+		return r
+	}, strings.ToLower(l.Msg))
+
+	tokens := []string{}
+	for _, token := range strings.Fields(stripped) {
+		if _, stop := StopWords[token]; !stop {
+			tokens = append(tokens, token)
+		}
+	}
+	return tokens
+}
+
+// RebuildInvertedIndex maps each token to the IDs of the logs containing it.
+func RebuildInvertedIndex(db *gorm.DB) (map[string][]uint, error) {
+	idx := map[string][]uint{}
+	logs := []Log{}
+	err := WrapDBError(db.FindInBatches(&logs, 500, func(tx *gorm.DB, _ int) error {
+		for _, l := range logs {
+			seen := map[string]bool{}
+			for _, token := range l.Tokenize() {
+				if !seen[token] {
+					seen[token] = true
+					idx[token] = append(idx[token], l.ID)
+				}
+			}
+		}
+		return nil
+	}))
+	return idx, err
+}
+
+// SearchIndex returns the sorted IDs of the logs containing every term.
+func SearchIndex(idx map[string][]uint, terms []string) []uint {
+	if len(terms) == 0 {
+		return nil
+	}
+	counts := map[uint]int{}
+	for _, term := range terms {
+		for _, id := range idx[strings.ToLower(term)] {
+			counts[id]++
+		}
+	}
+	ids := []uint{}
+	for id, n := range counts {
+		if n == len(terms) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Translation caches the translated Msg of a log per language.
+type Translation struct {
+	LogID        uint   `gorm:"primaryKey"`
+	Lang         string `gorm:"primaryKey"`
+	Text         string
+	TranslatedAt time.Time
+}
+
+type TranslationClient interface {
+	Translate(text, lang string) (string, error)
+}
+
+// TranslationClientFunc lets a plain function act as a TranslationClient.
+type TranslationClientFunc func(text, lang string) (string, error)
+
+func (f TranslationClientFunc) Translate(text, lang string) (string, error) {
+	return f(text, lang)
+}
+
+// Translate returns the cached translation of Msg, calling client and
+// caching its answer on a miss.
+func (l Log) Translate(db *gorm.DB, client TranslationClient, lang string) (string, error) {
+	cached := Translation{}
+	err := WrapDBError(db.Where("log_id = ? AND lang = ?", l.ID, lang).First(&cached))
+	if err == nil {
+		return cached.Text, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", err
+	}
+
+	text, err := client.Translate(l.Msg, lang)
+	if err != nil {
+		return "", err
+	}
+	translation := Translation{LogID: l.ID, Lang: lang, Text: text, TranslatedAt: time.Now()}
+	if err := WrapDBError(db.Create(&translation)); err != nil {
+		return "", err
+	}
+	return text, nil
+}
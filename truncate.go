@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TruncateAll empties the tables of models, and their many2many join tables,
+// in one transaction, for resetting a database between tests. Postgres gets a
+// single TRUNCATE ... RESTART IDENTITY of all of them, so foreign keys between
+// them don't get in the way; a table referencing them from outside the list
+// still does. Other databases get DELETE FROM, join tables first, and on
+// SQLite the table's AUTOINCREMENT counter, if it has one, is reset too. A
+// table that doesn't exist is logged as a warning and skipped; any other
+// failure rolls back every table.
+func TruncateAll(db *gorm.DB, models ...interface{}) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		joinTables, tables := []string{}, []string{}
+		seen := map[string]bool{}
+		add := func(list *[]string, table string) {
+			if seen[table] {
+				return
+			}
+			seen[table] = true
+			if !tx.Migrator().HasTable(table) {
+				tx.Logger.Warn(tx.Statement.Context, "TruncateAll: table %s doesn't exist", table)
+				return
+			}
+			*list = append(*list, table)
+		}
+		for _, model := range models {
+			stmt := &gorm.Statement{DB: tx}
+			if err := stmt.Parse(model); err != nil {
+				return err
+			}
+			for _, rel := range stmt.Schema.Relationships.Many2Many {
+				add(&joinTables, rel.JoinTable.Table)
+			}
+			add(&tables, stmt.Schema.Table)
+		}
+		tables = append(joinTables, tables...)
+		if len(tables) == 0 {
+			return nil
+		}
+
+		if tx.Dialector.Name() == "postgres" {
+			names := make([]interface{}, len(tables))
+			for i, table := range tables {
+				names[i] = clause.Table{Name: table}
+			}
+			sql := "TRUNCATE TABLE " + strings.TrimSuffix(strings.Repeat("?, ", len(tables)), ", ") + " RESTART IDENTITY"
+			return WrapDBError(tx.Exec(sql, names...))
+		}
+		for _, table := range tables {
+			if err := WrapDBError(tx.Exec("DELETE FROM ?", clause.Table{Name: table})); err != nil {
+				return err
+			}
+			if tx.Dialector.Name() == "sqlite" && tx.Migrator().HasTable("sqlite_sequence") {
+				if err := WrapDBError(tx.Exec("DELETE FROM sqlite_sequence WHERE name = ?", table)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// BulkUpsert inserts logs batchSize at a time, updating msg, level and time of
+// the rows whose id already exists, and returns how many rows were inserted
+// and how many updated. It stops at the first failing batch; the counts are
+// then those of the batches before it, which stay written.
+//
+// SQLite's RowsAffected counts inserted and updated rows alike, so the
+// existing ids of each batch are counted first to tell them apart.
+func BulkUpsert(db *gorm.DB, logs []Log, batchSize int) (inserted, updated int64, err error) {
+	if batchSize <= 0 {
+		return 0, 0, errors.New("BulkUpsert: batchSize must be positive")
+	}
+	upsert := clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"msg", "level", "time"}),
+	}
+	for _, batch := range ChunkLogs(logs, batchSize) {
+		ids := []uint{}
+		for _, log := range batch {
+			if log.ID != 0 {
+				ids = append(ids, log.ID)
+			}
+		}
+		existing := int64(0)
+		if len(ids) > 0 {
+			if err := WrapDBError(db.Unscoped().Model(&Log{}).Where("id IN ?", ids).Count(&existing)); err != nil {
+				return inserted, updated, err
+			}
+		}
+		result := db.Clauses(upsert).Create(&batch)
+		if err := WrapDBError(result); err != nil {
+			return inserted, updated, err
+		}
+		inserted += result.RowsAffected - existing
+		updated += existing
+	}
+	return inserted, updated, nil
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UpsertLog inserts log, or, if its ID is taken, updates only updateCols of
+// the existing row. updateCols are column or field names of Log; an unknown
+// one is an error and nothing is written.
+func UpsertLog(db *gorm.DB, log *Log, updateCols []string) error {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(&Log{}); err != nil {
+		return err
+	}
+	columns := make([]string, 0, len(updateCols))
+	for _, name := range updateCols {
+		field := stmt.Schema.LookUpField(name)
+		if field == nil || field.DBName == "" {
+			return fmt.Errorf("UpsertLog: %q is not a column of %s", name, stmt.Schema.Table)
+		}
+		columns = append(columns, field.DBName)
+	}
+
+	upsert := clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns(columns),
+	}
+	return WrapDBError(db.Clauses(upsert).Create(log))
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+// VacuumScheduler is a plugin that runs ManualVacuum once Threshold rows
+// (10,000 by default) have been deleted since the last run. Only hard deletes
+// count: a soft delete, such as db.Delete(&Log{}) without Unscoped, is an
+// UPDATE and frees nothing.
+type VacuumScheduler struct {
+	Threshold int64
+
+	deleted int64
+}
+
+func (s *VacuumScheduler) Name() string {
+	return "vacuum"
+}
+
+func (s *VacuumScheduler) Initialize(db *gorm.DB) error {
+	if s.Threshold <= 0 {
+		s.Threshold = 10000
+	}
+	// VACUUM cannot run inside a transaction, so wait for the delete's own
+	// transaction to commit. A delete inside a caller's transaction is still
+	// counted, but the VACUUM waits for a later delete outside one.
+	return db.Callback().Delete().After("gorm:commit_or_rollback_transaction").Register("vacuum:count", func(tx *gorm.DB) {
+		if tx.Error != nil || tx.Statement.DryRun {
+			return
+		}
+		if !strings.HasPrefix(tx.Statement.SQL.String(), "DELETE") {
+			return
+		}
+		if atomic.AddInt64(&s.deleted, tx.RowsAffected) < s.Threshold {
+			return
+		}
+		if _, inTx := tx.Statement.ConnPool.(gorm.TxCommitter); inTx {
+			return
+		}
+		if err := ManualVacuum(tx.Session(&gorm.Session{NewDB: true})); err != nil {
+			tx.Logger.Error(tx.Statement.Context, "vacuum: %v", err)
+			return
+		}
+		atomic.StoreInt64(&s.deleted, 0)
+	})
+}
+
+// ManualVacuum rebuilds the database file to reclaim the space of deleted
+// rows, then refreshes the query planner statistics.
+func ManualVacuum(db *gorm.DB) error {
+	if err := db.Exec("VACUUM").Error; err != nil {
+		return err
+	}
+	return db.Exec("ANALYZE").Error
+}
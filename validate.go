@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+)
+
+// ValidationError is one failed rule of a validate:"..." tag.
+type ValidationError struct {
+	Field string
+	Rule  string // "required", "min=1", ...
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s fails %s", e.Field, e.Rule)
+}
+
+// ValidationErrors holds every failed rule, not just the first.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return "validation: " + strings.Join(msgs, ", ")
+}
+
+// Validate checks the validate tags of the struct v points to (or is). The
+// rules are comma-separated:
+//
+//	required  the field is not its zero value
+//	min=N     a number is at least N, a string has at least N characters
+//	max=N     a number is at most N, a string has at most N characters
+//
+// It returns ValidationErrors if any rule fails, and a plain error for a tag
+// it can't parse.
+func Validate(v interface{}) error {
+	value := reflect.Indirect(reflect.ValueOf(v))
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("validate: %T is not a struct", v)
+	}
+	failed := ValidationErrors{}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		tag := field.Tag.Get("validate")
+		if tag == "" || !field.IsExported() {
+			continue
+		}
+		errs, err := validateField(field.Name, value.Field(i), tag)
+		if err != nil {
+			return err
+		}
+		failed = append(failed, errs...)
+	}
+	if len(failed) > 0 {
+		return failed
+	}
+	return nil
+}
+
+func validateField(name string, value reflect.Value, tag string) (ValidationErrors, error) {
+	failed := ValidationErrors{}
+	for _, rule := range strings.Split(tag, ",") {
+		rule = strings.TrimSpace(rule)
+		if rule == "required" {
+			if !value.IsValid() || value.IsZero() {
+				failed = append(failed, ValidationError{Field: name, Rule: rule})
+			}
+			continue
+		}
+		kind, param, ok := strings.Cut(rule, "=")
+		if !ok || (kind != "min" && kind != "max") {
+			return nil, fmt.Errorf("validate: %s: unknown rule %q", name, rule)
+		}
+		limit, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return nil, fmt.Errorf("validate: %s: bad number in %q", name, rule)
+		}
+		n, ok := measure(value)
+		if !ok {
+			continue // min and max don't apply to this kind, e.g. a gorm.Expr.
+		}
+		if (kind == "min" && n < limit) || (kind == "max" && n > limit) {
+			failed = append(failed, ValidationError{Field: name, Rule: rule})
+		}
+	}
+	return failed, nil
+}
+
+// measure returns what min and max compare: a string's length or a number.
+func measure(value reflect.Value) (float64, bool) {
+	switch value.Kind() {
+	case reflect.String:
+		return float64(utf8.RuneCountInString(value.String())), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// validateUpdate checks only the columns an update writes: the keys of a map,
+// or the selected (Save selects "*") or non-zero fields of a struct. The rest
+// of the row isn't checked, so Update("level", 5) passes on a loaded Log whose
+// Msg is invalid.
+func validateUpdate(stmt *gorm.Statement) error {
+	if stmt.Schema == nil {
+		return nil
+	}
+	failed := ValidationErrors{}
+	check := func(name string, value reflect.Value, tag string) error {
+		errs, err := validateField(name, value, tag)
+		failed = append(failed, errs...)
+		return err
+	}
+
+	switch dest := stmt.Dest.(type) {
+	case map[string]interface{}:
+		for key, v := range dest {
+			field := stmt.Schema.LookUpField(key)
+			if field == nil || field.Tag.Get("validate") == "" {
+				continue
+			}
+			if err := check(field.Name, reflect.ValueOf(v), field.Tag.Get("validate")); err != nil {
+				return err
+			}
+		}
+	default:
+		value := reflect.Indirect(reflect.ValueOf(dest))
+		if value.Kind() != reflect.Struct || value.Type() != stmt.Schema.ModelType {
+			return nil
+		}
+		selected, restricted := stmt.SelectAndOmitColumns(false, true)
+		for _, field := range stmt.Schema.Fields {
+			tag := field.Tag.Get("validate")
+			if tag == "" {
+				continue
+			}
+			fieldValue := value.FieldByIndex(field.StructField.Index)
+			written, ok := selected[field.DBName]
+			if !ok {
+				written = !restricted && !fieldValue.IsZero()
+			}
+			if !written {
+				continue
+			}
+			if err := check(field.Name, fieldValue, tag); err != nil {
+				return err
+			}
+		}
+	}
+	if len(failed) > 0 {
+		return failed
+	}
+	return nil
+}
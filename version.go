@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var ErrVersionConflict = errors.New("log was changed by someone else since it was loaded")
+
+const versionCheckKey = "version:check"
+
+// lockVersion makes an update of a loaded log (Version > 0) only match the
+// row while it is still at that version, and bumps Version. Updates through
+// a map bump the row's version even when the log was not loaded, so that
+// whoever did load it sees the conflict. This is done on update rather than
+// in BeforeSave, which also runs for inserts.
+//
+// Dry runs are left alone, since they never match a row, and so are the
+// updates Association issues, which only save the related rows.
+func lockVersion(tx *gorm.DB, u *Log) {
+	stmt := tx.Statement
+	if stmt.ReflectValue.Kind() != reflect.Struct || stmt.DryRun || !writesOwnColumns(stmt) {
+		return
+	}
+	if u.Version == 0 {
+		if _, ok := stmt.Dest.(map[string]interface{}); ok {
+			stmt.SetColumn("version", gorm.Expr("version + 1"))
+		} else {
+			stmt.Omits = append(stmt.Omits, "version") // Save would write the 0.
+		}
+		return
+	}
+	stmt.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "version"}, Value: u.Version},
+	}})
+	stmt.SetColumn("Version", u.Version+1)
+	stmt.DB.InstanceSet(versionCheckKey, true) // tx is a new session; stmt.DB runs the update.
+}
+
+// writesOwnColumns reports whether stmt may set a column of logs, and not
+// just save associations selected by name, such as Select("Tags").
+func writesOwnColumns(stmt *gorm.Statement) bool {
+	if len(stmt.Selects) == 0 {
+		return true
+	}
+	selected, _ := stmt.SelectAndOmitColumns(false, true)
+	for _, field := range stmt.Schema.Fields {
+		if field.DBName != "" && !field.PrimaryKey && selected[field.DBName] {
+			return true
+		}
+	}
+	return false
+}
+
+// checkVersion turns a locked update that matched no row into
+// ErrVersionConflict. Erroring here also stops Save from falling back to an
+// INSERT, which it does when an update affects no rows.
+func checkVersion(tx *gorm.DB) error {
+	update := tx.Statement.DB
+	if checked, _ := update.InstanceGet(versionCheckKey); checked == true && update.RowsAffected == 0 {
+		return ErrVersionConflict
+	}
+	return nil
+}
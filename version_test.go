@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVersionCheckSkipsAssociationSaves(t *testing.T) {
+	db, cleanup, err := InMemoryDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	tags := []Tag{{Name: "db"}, {Name: "auth"}, {Name: "slow"}}
+	if err := db.Create(&tags).Error; err != nil {
+		t.Fatal(err)
+	}
+	log := Log{Time: time.Now(), Msg: "tagged"}
+	if err := db.Create(&log).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Model(&log).Association("Tags").Append(&tags[0], &tags[1]); err != nil {
+		t.Fatalf("Append: %v", err)
+	}
+	if err := db.Model(&log).Association("Tags").Replace(&tags[1], &tags[2]); err != nil {
+		t.Fatalf("Replace: %v", err)
+	}
+	if n := db.Model(&log).Association("Tags").Count(); n != 2 {
+		t.Errorf("got %d tags, want 2", n)
+	}
+
+	// The log itself was never written, so its version still matches.
+	log.Level = 3
+	if err := db.Save(&log).Error; err != nil {
+		t.Fatalf("Save after the association saves: %v", err)
+	}
+}
+
+func TestVersionCheckSkipsDryRuns(t *testing.T) {
+	db, cleanup, err := InMemoryDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanup()
+	log := Log{Time: time.Now(), Msg: "dry run"}
+	if err := db.Create(&log).Error; err != nil {
+		t.Fatal(err)
+	}
+	loaded := Log{}
+	if err := db.First(&loaded, log.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	loaded.Level = 5
+	if err := WithDryRun(db).Save(&loaded).Error; err != nil {
+		t.Errorf("dry-run Save: %v", err)
+	}
+	if err := WithDryRun(db).Model(&loaded).Update("level", 5).Error; err != nil {
+		t.Errorf("dry-run Update: %v", err)
+	}
+	if loaded.Version != 1 {
+		t.Errorf("dry runs bumped Version to %d", loaded.Version)
+	}
+
+	stored := Log{}
+	if err := db.First(&stored, log.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.Level != 0 || stored.Version != 1 {
+		t.Errorf("dry runs wrote level %d, version %d", stored.Level, stored.Version)
+	}
+}
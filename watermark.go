@@ -0,0 +1,21 @@
+package main
+
+import "gorm.io/gorm"
+
+// FindSince returns up to limit logs with an ID above watermarkID, and the
+// watermark to pass next time.
+func FindSince(db *gorm.DB, watermarkID uint, limit int) ([]Log, uint, error) {
+	logs := []Log{}
+	err := WrapDBError(db.
+		Where("id > ?", watermarkID).
+		Order("id ASC").
+		Limit(limit).
+		Find(&logs))
+	if err != nil {
+		return nil, watermarkID, err
+	}
+	if len(logs) > 0 {
+		watermarkID = logs[len(logs)-1].ID
+	}
+	return logs, watermarkID, nil
+}
@@ -0,0 +1,41 @@
+package main
+
+import "gorm.io/gorm"
+
+// WithTransaction begins a transaction and returns it with functions to commit
+// and to roll it back. Use the tx for everything until one of those is
+// called; after either, the tx is finished and must not be used again.
+//
+// Rollback after a successful Commit does nothing and returns nil, so it can
+// be deferred right away:
+//
+//	tx, commit, rollback, err := WithTransaction(db)
+//	if err != nil {
+//		return err
+//	}
+//	defer rollback()
+//	if err := tx.Create(&log).Error; err != nil {
+//		return err // rolled back
+//	}
+//	return commit()
+func WithTransaction(db *gorm.DB) (*gorm.DB, func() error, func() error, error) {
+	tx := db.Begin()
+	if tx.Error != nil {
+		return nil, nil, nil, tx.Error
+	}
+	committed := false
+	commit := func() error {
+		if err := tx.Commit().Error; err != nil {
+			return err
+		}
+		committed = true
+		return nil
+	}
+	rollback := func() error {
+		if committed {
+			return nil
+		}
+		return tx.Rollback().Error
+	}
+	return tx, commit, rollback, nil
+}
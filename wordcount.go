@@ -0,0 +1,34 @@
+package main
+
+import (
+	"database/sql"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Spaces plus one, which is close enough to strings.Fields on single-spaced messages.
+const wordCountSQL = "length(msg) - length(replace(msg, ' ', '')) + 1"
+
+func (l Log) WordCount() int {
+	return len(strings.Fields(l.Msg))
+}
+
+// FindByMinWordCount returns the logs whose Msg has at least n words.
+func FindByMinWordCount(db *gorm.DB, n int) ([]Log, error) {
+	logs := []Log{}
+	err := WrapDBError(db.Where(wordCountSQL+" >= ?", n).Find(&logs))
+	return logs, err
+}
+
+// All zeros when there are no logs.
+func WordCountStats(db *gorm.DB) (min, max int64, avg float64, err error) {
+	var nMin, nMax sql.NullInt64
+	var nAvg sql.NullFloat64
+	err = db.
+		Model(&Log{}).
+		Select("MIN("+wordCountSQL+"), MAX("+wordCountSQL+"), AVG("+wordCountSQL+")").
+		Row().
+		Scan(&nMin, &nMax, &nAvg)
+	return nMin.Int64, nMax.Int64, nAvg.Float64, err
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+var ErrWritesLocked = errors.New("writes are locked")
+
+// WriteBarrier is a plugin that rejects new creates, updates and deletes
+// with ErrWritesLocked between Lock and Unlock. Lock returns once the writes
+// already in flight are done.
+type WriteBarrier struct {
+	locked   int32
+	inFlight sync.RWMutex
+}
+
+func (b *WriteBarrier) Name() string {
+	return "write_barrier"
+}
+
+func (b *WriteBarrier) Initialize(db *gorm.DB) error {
+	enter := func(tx *gorm.DB) {
+		if atomic.LoadInt32(&b.locked) == 1 {
+			tx.AddError(ErrWritesLocked)
+			return
+		}
+		b.inFlight.RLock()
+		tx.InstanceSet("write_barrier:entered", true)
+	}
+	leave := func(tx *gorm.DB) {
+		if _, ok := tx.InstanceGet("write_barrier:entered"); ok {
+			b.inFlight.RUnlock()
+		}
+	}
+	cb := db.Callback()
+	for _, err := range []error{
+		cb.Create().Before("gorm:before_create").Register("write_barrier:enter", enter),
+		cb.Update().Before("gorm:before_update").Register("write_barrier:enter", enter),
+		cb.Delete().Before("gorm:before_delete").Register("write_barrier:enter", enter),
+		cb.Create().After("*").Register("write_barrier:leave", leave),
+		cb.Update().After("*").Register("write_barrier:leave", leave),
+		cb.Delete().After("*").Register("write_barrier:leave", leave),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *WriteBarrier) Lock() {
+	atomic.StoreInt32(&b.locked, 1)
+	b.inFlight.Lock()
+}
+
+func (b *WriteBarrier) Unlock() {
+	b.inFlight.Unlock()
+	atomic.StoreInt32(&b.locked, 0)
+}
+
+// BackupDB copies the SQLite database to destPath with VACUUM INTO (SQLite
+// 3.27+), holding the WriteBarrier registered on db, if any, meanwhile.
+func BackupDB(db *gorm.DB, destPath string) error {
+	if plugin, ok := db.Config.Plugins[(&WriteBarrier{}).Name()]; ok {
+		barrier := plugin.(*WriteBarrier)
+		barrier.Lock()
+		defer barrier.Unlock()
+	}
+	return WrapDBError(db.Exec("VACUUM INTO ?", destPath))
+}